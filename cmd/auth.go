@@ -0,0 +1,58 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+var authCommand = cli.Command{
+	Name:  "auth",
+	Usage: "Inspect the GitHub credentials used by ghmt",
+	Subcommands: []cli.Command{
+		{
+			Name:  "status",
+			Usage: "Report which token source is used and the scopes granted to the token",
+			Action: func(ctx *cli.Context) error {
+				ghProxy, err := newProxy(ctx)
+				if err != nil {
+					return err
+				}
+
+				status, err := ghProxy.GetAuthStatus()
+				if err != nil {
+					return err
+				}
+
+				fmt.Printf("Token source: %s\n", status.Source)
+				if status.Login == "" {
+					return nil
+				}
+				scopes := strings.Join(status.Scopes, ", ")
+				if scopes == "" {
+					scopes = "none reported"
+				}
+				fmt.Printf("Logged in as: %s\n", status.Login)
+				fmt.Printf("Token scopes: %s\n", scopes)
+				return nil
+			},
+		},
+	},
+}
@@ -0,0 +1,51 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sbuckfelder/github-monitoring-tool/proxy"
+	"github.com/urfave/cli"
+)
+
+var cacheCommand = cli.Command{
+	Name:  "cache",
+	Usage: "Manage the HTTP response cache",
+	Subcommands: []cli.Command{
+		{
+			Name:  "clear",
+			Usage: "Remove all cached responses",
+			Action: func(ctx *cli.Context) error {
+				conf, err := loadConfig(ctx)
+				if err != nil {
+					return err
+				}
+				dir := cacheDir(ctx, conf)
+				if dir == "" {
+					return errors.New("No cache directory is configured")
+				}
+				if err := proxy.ClearCache(dir); err != nil {
+					return err
+				}
+				fmt.Printf("Cleared cache %s\n", dir)
+				return nil
+			},
+		},
+	},
+}
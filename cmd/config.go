@@ -0,0 +1,85 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli"
+)
+
+const CONFIG_FILE string = ".ghmt.json"
+
+type config struct {
+	APIURL      string `json:"api_url"`
+	WebURL      string `json:"web_url"`
+	Concurrency int    `json:"concurrency"`
+	CacheDir    string `json:"cache_dir"`
+	TimeZone    string `json:"timezone"`
+	Store       string `json:"store"`
+	// SyncRepos lists the org/repo pairs pulled by sync when no org is given.
+	SyncRepos []string `json:"sync_repos"`
+	// MaintainerTeams and ResponseThreshold are defaults for the sla and
+	// issues commands.
+	MaintainerTeams   []string `json:"maintainer_teams"`
+	ResponseThreshold string   `json:"response_threshold"`
+}
+
+func getConfigFileName() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, CONFIG_FILE)
+}
+
+// loadConfig reads the file given by --config, falling back to ~/.ghmt.json
+// which is allowed to be missing.
+func loadConfig(ctx *cli.Context) (*config, error) {
+	fileName := ctx.GlobalString(CONFIG_NAME)
+	explicit := fileName != ""
+	if !explicit {
+		fileName = getConfigFileName()
+	}
+	conf := &config{}
+	data, err := ioutil.ReadFile(fileName)
+	if os.IsNotExist(err) && !explicit {
+		return conf, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read config %s: %v", fileName, err)
+	}
+	if err := json.Unmarshal(data, conf); err != nil {
+		return nil, fmt.Errorf("Failed to parse config %s: %v", fileName, err)
+	}
+	return conf, nil
+}
+
+func stringOption(ctx *cli.Context, flagName, configValue string) string {
+	if value := ctx.GlobalString(flagName); value != "" {
+		return value
+	}
+	return configValue
+}
+
+func intOption(ctx *cli.Context, flagName string, configValue int) int {
+	if ctx.GlobalIsSet(flagName) {
+		return ctx.GlobalInt(flagName)
+	}
+	return configValue
+}
@@ -0,0 +1,77 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sbuckfelder/github-monitoring-tool/proxy"
+)
+
+const (
+	EXIT_ERROR         int = 1
+	EXIT_USAGE         int = 2
+	EXIT_MISSING_TOKEN int = 3
+	EXIT_INVALID_TIME  int = 4
+	EXIT_API           int = 5
+	EXIT_NOT_FOUND     int = 6
+	EXIT_RATE_LIMITED  int = 7
+)
+
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+func exitCode(err error) int {
+	var usageErr *usageError
+	var tokenErr *proxy.MissingTokenError
+	var timeErr *proxy.InvalidTimeError
+	var notFoundErr *proxy.NotFoundError
+	var rateErr *proxy.RateLimitedError
+	var apiErr *proxy.APIError
+	switch {
+	case errors.As(err, &usageErr), isRequiredFlagsError(err):
+		return EXIT_USAGE
+	case errors.As(err, &tokenErr):
+		return EXIT_MISSING_TOKEN
+	case errors.As(err, &timeErr):
+		return EXIT_INVALID_TIME
+	case errors.As(err, &notFoundErr):
+		return EXIT_NOT_FOUND
+	case errors.As(err, &rateErr):
+		return EXIT_RATE_LIMITED
+	case errors.As(err, &apiErr):
+		return EXIT_API
+	default:
+		return EXIT_ERROR
+	}
+}
+
+// isRequiredFlagsError matches the error urfave/cli returns for missing
+// required flags, whose type is unexported.
+func isRequiredFlagsError(err error) bool {
+	return fmt.Sprintf("%T", err) == "*cli.errRequiredFlags"
+}
@@ -17,25 +17,41 @@
 package main
 
 import (
-	"errors"
+	"fmt"
+	"os"
+	"strings"
 
 	"github.com/sbuckfelder/github-monitoring-tool/proxy"
+	"github.com/sbuckfelder/github-monitoring-tool/render"
 	"github.com/urfave/cli"
 )
 
 const (
-	ORG_NAME     string = "org"
-	REPOALL_NAME string = "repoall"
-	REPO_NAME    string = "repo"
-	SINCE_NAME   string = "since"
-	DATE_NAME    string = "date"
-	HOURS_NAME   string = "hours"
+	ORG_NAME           string = "org"
+	REPOALL_NAME       string = "repoall"
+	REPO_NAME          string = "repo"
+	SINCE_NAME         string = "since"
+	DATE_NAME          string = "date"
+	HOURS_NAME         string = "hours"
+	FROM_NAME          string = "from"
+	TO_NAME            string = "to"
+	LAST_NAME          string = "last"
+	WEEK_NAME          string = "week"
+	MONTH_NAME         string = "month"
+	OUTPUT_NAME        string = "output"
+	SOURCE_NAME        string = "source"
+	OFFLINE_NAME       string = "offline"
+	FILL_GAPS_NAME     string = "fill-gaps"
+	TYPES_NAME         string = "types"
+	EXCLUDE_TYPES_NAME string = "exclude-types"
+	ACTOR_NAME         string = "actor"
+	EXCLUDE_ACTOR_NAME string = "exclude-actor"
 )
 
 var eventsCommand = cli.Command{
 	Name:  "events",
-	Usage: "List events for a github org/repo provide one of [hours,date,since]",
-	Flags: []cli.Flag{
+	Usage: "List events for a github org/repo provide one of [since,hours,date,from,last,week,month]",
+	Flags: append([]cli.Flag{
 		cli.StringFlag{
 			Name:     ORG_NAME,
 			Usage:    "github org repo belongs to",
@@ -53,7 +69,7 @@ var eventsCommand = cli.Command{
 		},
 		cli.StringFlag{
 			Name:     SINCE_NAME,
-			Usage:    "timestamp in RFC3339 format e.g. 2006-01-02T15:04:05Z",
+			Usage:    "timestamp in RFC3339 format e.g. 2006-01-02T15:04:05Z, without an offset it is read in --tz",
 			Required: false,
 		},
 		cli.IntFlag{
@@ -66,64 +82,180 @@ var eventsCommand = cli.Command{
 			Usage:    "date for events format YYYY-MM-DD",
 			Required: false,
 		},
-	},
+		cli.StringFlag{
+			Name:  FROM_NAME,
+			Usage: "start of a closed range, date YYYY-MM-DD or RFC3339 timestamp",
+		},
+		cli.StringFlag{
+			Name:  TO_NAME,
+			Usage: "end of the range started by from, a date includes the whole day (default now)",
+		},
+		cli.StringFlag{
+			Name:  LAST_NAME,
+			Usage: "lookback duration in hours, days or weeks e.g. 12h, 3d, 2w",
+		},
+		cli.StringFlag{
+			Name:  WEEK_NAME,
+			Usage: "ISO 8601 week e.g. 2026-W41",
+		},
+		cli.StringFlag{
+			Name:  MONTH_NAME,
+			Usage: "calendar month format YYYY-MM",
+		},
+		cli.StringSliceFlag{
+			Name:  TYPES_NAME,
+			Usage: "only include events of these types e.g. PullRequestEvent,PullRequestReviewEvent",
+		},
+		cli.StringSliceFlag{
+			Name:  EXCLUDE_TYPES_NAME,
+			Usage: "drop events of these types e.g. WatchEvent,ForkEvent",
+		},
+		cli.StringSliceFlag{
+			Name:  ACTOR_NAME,
+			Usage: "only include events by these actors, glob or re: prefixed regex",
+		},
+		cli.StringSliceFlag{
+			Name:  EXCLUDE_ACTOR_NAME,
+			Usage: "drop events by these actors, glob or re: prefixed regex e.g. renovate*",
+		},
+		cli.BoolFlag{
+			Name:  EXCLUDE_BOTS_NAME,
+			Usage: "drop events by bot accounts",
+		},
+		cli.StringFlag{
+			Name:  SOURCE_NAME,
+			Usage: "where events come from one of [" + strings.Join(proxy.EVENT_SOURCES, ",") + "], search reaches past the 90 day events horizon",
+			Value: proxy.EVENT_SOURCE_EVENTS,
+		},
+		cli.BoolFlag{
+			Name:  FILL_GAPS_NAME,
+			Usage: "fill the part of the window the events API no longer returns from the search API",
+		},
+		cli.BoolFlag{
+			Name:  OFFLINE_NAME,
+			Usage: "report from the local event store written by sync, without calling the API",
+		},
+		cli.StringFlag{
+			Name:     OUTPUT_NAME,
+			Usage:    "output format one of [" + strings.Join(render.EventFormats(), ",") + "]",
+			Value:    render.DEFAULT_FORMAT,
+			Required: false,
+		},
+	}, repoFilterFlags...),
 	Action: func(ctx *cli.Context) error {
 		orgInput := ctx.String(ORG_NAME)
-		repoAllInput := ctx.Bool(REPOALL_NAME)
-		repoInput := ctx.String(REPO_NAME)
-		sinceInput := ctx.String(SINCE_NAME)
-		dateInput := ctx.String(DATE_NAME)
-		hoursInput := ctx.Int(HOURS_NAME)
-
-		if repoAllInput && repoInput != "" {
-			return errors.New("Both 'repo' and 'repoall' flag cannot be set")
-		}
+		outputInput := ctx.String(OUTPUT_NAME)
+		sourceInput := ctx.String(SOURCE_NAME)
+		offlineInput := ctx.Bool(OFFLINE_NAME)
 
-		if !repoAllInput && repoInput == "" {
-			return errors.New("Either 'repo' or 'repoall' needs to be set")
+		renderer, err := render.NewEventRenderer(outputInput)
+		if err != nil {
+			return &usageError{msg: err.Error()}
 		}
 
-		if sinceInput == "" && dateInput == "" && hoursInput == 0 {
-			return errors.New("No time flag [since,date,hours] is set")
+		if sourceInput != proxy.EVENT_SOURCE_EVENTS && sourceInput != proxy.EVENT_SOURCE_SEARCH {
+			return usageErrorf("Unknown source '%s' must be one of [%s]",
+				sourceInput, strings.Join(proxy.EVENT_SOURCES, ","))
 		}
 
-		if sinceInput != "" && dateInput != "" {
-			return errors.New("Cannot have both since and date set")
+		if offlineInput && ctx.IsSet(SOURCE_NAME) {
+			return usageErrorf("Cannot have both source and offline set")
 		}
 
-		if sinceInput != "" && hoursInput != 0 {
-			return errors.New("Cannot have both since and hours set")
+		fillGapsInput := ctx.Bool(FILL_GAPS_NAME)
+		if fillGapsInput && (offlineInput || sourceInput != proxy.EVENT_SOURCE_EVENTS) {
+			return usageErrorf("fill-gaps can only be used with the events source")
 		}
 
-		if dateInput != "" && hoursInput != 0 {
-			return errors.New("Cannot have both date and hours set")
+		if err := validateRepoFlags(ctx); err != nil {
+			return err
 		}
 
-		ghProxy, err := proxy.NewProxy()
-		if err != nil {
-			panic("Failed to create GitHub client")
+		window := proxy.TimeWindowSpec{
+			Since: ctx.String(SINCE_NAME),
+			Hours: ctx.Int(HOURS_NAME),
+			Date:  ctx.String(DATE_NAME),
+			From:  ctx.String(FROM_NAME),
+			To:    ctx.String(TO_NAME),
+			Last:  ctx.String(LAST_NAME),
+			Week:  ctx.String(WEEK_NAME),
+			Month: ctx.String(MONTH_NAME),
 		}
-
-		var repos = []string{}
-
-		if repoAllInput {
-			repos = ghProxy.GetReposForOrg(orgInput)
-		} else {
-			repos = append(repos, repoInput)
+		if err := window.Validate(); err != nil {
+			return &usageError{msg: err.Error()}
 		}
 
-		if dateInput != "" {
-			ghProxy.GetEventsForDate(orgInput, repos, dateInput)
+		filter := proxy.EventFilter{
+			Types:         listOption(ctx, TYPES_NAME),
+			ExcludeTypes:  listOption(ctx, EXCLUDE_TYPES_NAME),
+			Actors:        listOption(ctx, ACTOR_NAME),
+			ExcludeActors: listOption(ctx, EXCLUDE_ACTOR_NAME),
+			ExcludeBots:   ctx.Bool(EXCLUDE_BOTS_NAME),
 		}
-
-		if sinceInput != "" {
-			ghProxy.GetEventsSinceRFC3339(orgInput, repos, sinceInput)
+		if err := filter.Validate(); err != nil {
+			return &usageError{msg: err.Error()}
 		}
 
-		if hoursInput != 0 {
-			ghProxy.GetEventsForHours(orgInput, repos, hoursInput)
+		var report *proxy.EventReport
+		if offlineInput {
+			report, err = storedEventReport(ctx, orgInput, window, filter)
+		} else {
+			report, err = eventReport(ctx, orgInput, sourceInput, fillGapsInput, window, filter)
+		}
+		if err != nil {
+			return err
 		}
 
-		return nil
+		warnTruncated(report)
+		return renderer.RenderEvents(os.Stdout, report)
 	},
 }
+
+func eventReport(
+	ctx *cli.Context,
+	org, source string,
+	fillGaps bool,
+	window proxy.TimeWindowSpec,
+	filter proxy.EventFilter) (*proxy.EventReport, error) {
+	ghProxy, err := newProxy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	repos, err := resolveRepos(ctx, ghProxy)
+	if err != nil {
+		return nil, err
+	}
+	if source == proxy.EVENT_SOURCE_SEARCH {
+		return ghProxy.GetEventsFromSearch(org, repos, window, filter)
+	}
+	if fillGaps {
+		return ghProxy.GetEventsFillingGaps(org, repos, window, filter)
+	}
+	return ghProxy.GetEvents(org, repos, window, filter)
+}
+
+func storedEventReport(
+	ctx *cli.Context,
+	org string,
+	window proxy.TimeWindowSpec,
+	filter proxy.EventFilter) (*proxy.EventReport, error) {
+	ghProxy, err := newOfflineProxy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	repos, err := resolveStoredRepos(ctx, ghProxy)
+	if err != nil {
+		return nil, err
+	}
+	return ghProxy.GetEventsFromStore(org, repos, window, filter)
+}
+
+// warnTruncated goes to stderr so it is seen whatever the output format.
+func warnTruncated(report *proxy.EventReport) {
+	for _, repo := range report.Repos {
+		if repo.Truncated {
+			fmt.Fprintf(os.Stderr, "warning: %s/%s events only available %s, report is incomplete\n",
+				report.Org, repo.Repo, repo.Coverage())
+		}
+	}
+}
@@ -0,0 +1,135 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/sbuckfelder/github-monitoring-tool/proxy"
+	"github.com/urfave/cli"
+)
+
+const (
+	EXCLUDE_ARCHIVED_NAME string = "exclude-archived"
+	EXCLUDE_FORKS_NAME    string = "exclude-forks"
+	INCLUDE_REPO_NAME     string = "include-repo"
+	EXCLUDE_REPO_NAME     string = "exclude-repo"
+	TOPIC_NAME            string = "topic"
+	VISIBILITY_NAME       string = "visibility"
+	LANGUAGE_NAME         string = "language"
+)
+
+var repoFilterFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  EXCLUDE_ARCHIVED_NAME,
+		Usage: "with repoall, skip archived repos",
+	},
+	cli.BoolFlag{
+		Name:  EXCLUDE_FORKS_NAME,
+		Usage: "with repoall, skip forked repos",
+	},
+	cli.StringSliceFlag{
+		Name:  INCLUDE_REPO_NAME,
+		Usage: "with repoall, only repos matching a glob, or a regex prefixed with re:",
+	},
+	cli.StringSliceFlag{
+		Name:  EXCLUDE_REPO_NAME,
+		Usage: "with repoall, skip repos matching a glob, or a regex prefixed with re:",
+	},
+	cli.StringSliceFlag{
+		Name:  TOPIC_NAME,
+		Usage: "with repoall, only repos with one of these topics",
+	},
+	cli.StringFlag{
+		Name:  VISIBILITY_NAME,
+		Usage: "with repoall, only repos with this visibility one of [all,public,private,internal]",
+	},
+	cli.StringSliceFlag{
+		Name:  LANGUAGE_NAME,
+		Usage: "with repoall, only repos with one of these primary languages",
+	},
+}
+
+func validateRepoFlags(ctx *cli.Context) error {
+	if ctx.Bool(REPOALL_NAME) && ctx.String(REPO_NAME) != "" {
+		return usageErrorf("Both 'repo' and 'repoall' flag cannot be set")
+	}
+
+	if !ctx.Bool(REPOALL_NAME) && ctx.String(REPO_NAME) == "" {
+		return usageErrorf("Either 'repo' or 'repoall' needs to be set")
+	}
+
+	if err := repoFilterFromContext(ctx).Validate(); err != nil {
+		return &usageError{msg: err.Error()}
+	}
+	return nil
+}
+
+func resolveRepos(ctx *cli.Context, ghProxy *proxy.GithubProxy) ([]string, error) {
+	if ctx.Bool(REPOALL_NAME) {
+		return ghProxy.GetReposForOrg(ctx.String(ORG_NAME), repoFilterFromContext(ctx))
+	}
+	return []string{ctx.String(REPO_NAME)}, nil
+}
+
+// resolveStoredRepos is resolveRepos for reports from the event store.
+func resolveStoredRepos(ctx *cli.Context, ghProxy *proxy.GithubProxy) ([]string, error) {
+	if ctx.Bool(REPOALL_NAME) {
+		return ghProxy.GetStoredReposForOrg(ctx.String(ORG_NAME), repoFilterFromContext(ctx))
+	}
+	return []string{ctx.String(REPO_NAME)}, nil
+}
+
+func repoFilterFromContext(ctx *cli.Context) proxy.RepoFilter {
+	return proxy.RepoFilter{
+		ExcludeArchived: ctx.Bool(EXCLUDE_ARCHIVED_NAME),
+		ExcludeForks:    ctx.Bool(EXCLUDE_FORKS_NAME),
+		Include:         listOption(ctx, INCLUDE_REPO_NAME),
+		Exclude:         listOption(ctx, EXCLUDE_REPO_NAME),
+		Topics:          listOption(ctx, TOPIC_NAME),
+		Visibility:      ctx.String(VISIBILITY_NAME),
+		Languages:       listOption(ctx, LANGUAGE_NAME),
+	}
+}
+
+// listOption accepts both repeated flags and comma separated values.
+func listOption(ctx *cli.Context, name string) []string {
+	var values []string
+	for _, value := range ctx.StringSlice(name) {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+	return values
+}
+
+// dateOption accepts a YYYY-MM-DD date or a timestamp, read in loc when it
+// has no offset, an unset flag is the zero time.
+func dateOption(ctx *cli.Context, name string, loc *time.Location) (time.Time, error) {
+	value := ctx.String(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := proxy.ParseDayOrTimestamp(value, loc)
+	if err != nil {
+		return time.Time{}, &proxy.InvalidTimeError{Value: value, Format: "YYYY-MM-DD or RFC3339", Err: err}
+	}
+	return date, nil
+}
@@ -0,0 +1,93 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/sbuckfelder/github-monitoring-tool/render"
+	"github.com/urfave/cli"
+)
+
+var issuesCommand = cli.Command{
+	Name:  "issues",
+	Usage: "List open issues for a github org/repo.  Meant to identify issues waiting on maintainers for triage.",
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:     ORG_NAME,
+			Usage:    "github org repo belongs to",
+			Required: true,
+		},
+		cli.BoolFlag{
+			Name:     REPOALL_NAME,
+			Usage:    "list issues for all repos in an organization, cannot be used with repo flag",
+			Required: false,
+		},
+		cli.StringFlag{
+			Name:     REPO_NAME,
+			Usage:    "github repo to list issues for, cannot be used with repoall flag",
+			Required: false,
+		},
+		cli.StringSliceFlag{
+			Name:  COLUMNS_NAME,
+			Usage: "columns to output in order, from [" + strings.Join(render.IssueColumnNames(), ",") + "]",
+		},
+		cli.BoolFlag{
+			Name:  NO_HEADER_NAME,
+			Usage: "do not write the header row",
+		},
+		maintainerTeamFlag,
+	}, repoFilterFlags...),
+	Action: func(ctx *cli.Context) error {
+		orgInput := ctx.String(ORG_NAME)
+
+		if err := validateRepoFlags(ctx); err != nil {
+			return err
+		}
+
+		columns, err := render.SelectIssueColumns(listOption(ctx, COLUMNS_NAME))
+		if err != nil {
+			return &usageError{msg: err.Error()}
+		}
+
+		conf, err := loadConfig(ctx)
+		if err != nil {
+			return err
+		}
+
+		ghProxy, err := newProxy(ctx)
+		if err != nil {
+			return err
+		}
+
+		repos, err := resolveRepos(ctx, ghProxy)
+		if err != nil {
+			return err
+		}
+
+		report, err := ghProxy.GetIssues(orgInput, repos, maintainerOptions(ctx, conf))
+		if err != nil {
+			return err
+		}
+
+		return render.IssueReport(os.Stdout, report, render.IssueOptions{
+			Columns:  columns,
+			NoHeader: ctx.Bool(NO_HEADER_NAME),
+		})
+	},
+}
@@ -29,7 +29,7 @@ func main() {
 	app := App()
 	if err := app.Run(os.Args); err != nil {
 		fmt.Fprintf(os.Stderr, "github-monitoring-tool (ghmt): %s\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -40,9 +40,30 @@ func App() *cli.App {
 	app.Usage = usage
 	app.Description = `
 Simple CLI tool to help monitor and manage projects hosted on GitHub.`
+	app.Flags = globalFlags
 	app.Commands = []cli.Command{
 		eventsCommand,
 		prCommand,
+		issuesCommand,
+		slaCommand,
+		authCommand,
+		cacheCommand,
+		syncCommand,
 	}
+	app.OnUsageError = onUsageError
+	setOnUsageError(app.Commands)
 	return app
 }
+
+// onUsageError turns flag parsing errors into usage errors, so they exit
+// with EXIT_USAGE like the flag checks of the commands.
+func onUsageError(ctx *cli.Context, err error, isSubcommand bool) error {
+	return &usageError{msg: err.Error()}
+}
+
+func setOnUsageError(commands []cli.Command) {
+	for i := range commands {
+		commands[i].OnUsageError = onUsageError
+		setOnUsageError(commands[i].Subcommands)
+	}
+}
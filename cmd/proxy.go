@@ -0,0 +1,186 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"time"
+
+	"github.com/sbuckfelder/github-monitoring-tool/proxy"
+	"github.com/urfave/cli"
+)
+
+const (
+	TOKEN_NAME               string = "token"
+	TOKEN_FILE_NAME          string = "token-file"
+	APP_ID_NAME              string = "app-id"
+	APP_INSTALLATION_ID_NAME string = "app-installation-id"
+	APP_PRIVATE_KEY_NAME     string = "app-private-key"
+	CONFIG_NAME              string = "config"
+	API_URL_NAME             string = "api-url"
+	WEB_URL_NAME             string = "web-url"
+	CONCURRENCY_NAME         string = "concurrency"
+	CACHE_DIR_NAME           string = "cache-dir"
+	NO_CACHE_NAME            string = "no-cache"
+	TZ_NAME                  string = "tz"
+	STORE_NAME               string = "store"
+)
+
+var globalFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  CONFIG_NAME,
+		Usage: "JSON config file (default ~/" + CONFIG_FILE + ")",
+	},
+	cli.StringFlag{
+		Name:   API_URL_NAME,
+		Usage:  "GitHub API URL, set for GitHub Enterprise Server e.g. https://github.example.com/api/v3/",
+		EnvVar: "GHMT_API_URL",
+	},
+	cli.StringFlag{
+		Name:   WEB_URL_NAME,
+		Usage:  "GitHub web URL used in report links, derived from the API URL when not set",
+		EnvVar: "GHMT_WEB_URL",
+	},
+	cli.IntFlag{
+		Name:  CONCURRENCY_NAME,
+		Usage: "number of repositories fetched in parallel (default 4)",
+	},
+	cli.StringFlag{
+		Name:  CACHE_DIR_NAME,
+		Usage: "directory for the HTTP response cache (default " + proxy.DefaultCacheDir() + ")",
+	},
+	cli.BoolFlag{
+		Name:  NO_CACHE_NAME,
+		Usage: "do not read or write the HTTP response cache",
+	},
+	cli.StringFlag{
+		Name:  STORE_NAME,
+		Usage: "local event store written by sync (default " + proxy.DefaultStorePath() + ")",
+	},
+	cli.StringFlag{
+		Name:   TZ_NAME,
+		Usage:  "IANA time zone for report dates and times e.g. America/Los_Angeles (default UTC)",
+		EnvVar: "GHMT_TZ",
+	},
+	cli.StringFlag{
+		Name:  TOKEN_NAME,
+		Usage: "GitHub token, takes precedence over all other token sources",
+	},
+	cli.StringFlag{
+		Name:  TOKEN_FILE_NAME,
+		Usage: "file containing a GitHub token",
+	},
+	cli.Int64Flag{
+		Name:   APP_ID_NAME,
+		Usage:  "authenticate as this GitHub App instead of with a token",
+		EnvVar: "GHMT_APP_ID",
+	},
+	cli.Int64Flag{
+		Name:   APP_INSTALLATION_ID_NAME,
+		Usage:  "installation of the GitHub App to authenticate as",
+		EnvVar: "GHMT_APP_INSTALLATION_ID",
+	},
+	cli.StringFlag{
+		Name:   APP_PRIVATE_KEY_NAME,
+		Usage:  "PEM file with the private key of the GitHub App",
+		EnvVar: "GHMT_APP_PRIVATE_KEY",
+	},
+}
+
+func newProxy(ctx *cli.Context) (*proxy.GithubProxy, error) {
+	opts, err := proxyOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return proxy.NewProxy(opts)
+}
+
+// newOfflineProxy needs no credentials, for reports from the event store.
+func newOfflineProxy(ctx *cli.Context) (*proxy.GithubProxy, error) {
+	opts, err := proxyOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return proxy.NewOfflineProxy(opts)
+}
+
+func proxyOptions(ctx *cli.Context) (proxy.Options, error) {
+	conf, err := loadConfig(ctx)
+	if err != nil {
+		return proxy.Options{}, err
+	}
+	location, err := timeZone(ctx, conf)
+	if err != nil {
+		return proxy.Options{}, err
+	}
+	opts := proxy.Options{
+		APIURL:      stringOption(ctx, API_URL_NAME, conf.APIURL),
+		WebURL:      stringOption(ctx, WEB_URL_NAME, conf.WebURL),
+		Concurrency: intOption(ctx, CONCURRENCY_NAME, conf.Concurrency),
+		CacheDir:    cacheDir(ctx, conf),
+		Location:    location,
+		StorePath:   storePath(ctx, conf),
+		Token: proxy.TokenOptions{
+			Token:     ctx.GlobalString(TOKEN_NAME),
+			TokenFile: ctx.GlobalString(TOKEN_FILE_NAME),
+		},
+		App: proxy.AppOptions{
+			AppID:          ctx.GlobalInt64(APP_ID_NAME),
+			InstallationID: ctx.GlobalInt64(APP_INSTALLATION_ID_NAME),
+			PrivateKeyFile: ctx.GlobalString(APP_PRIVATE_KEY_NAME),
+		},
+	}
+	return opts, nil
+}
+
+func cacheDir(ctx *cli.Context, conf *config) string {
+	if ctx.GlobalBool(NO_CACHE_NAME) {
+		return ""
+	}
+	if dir := stringOption(ctx, CACHE_DIR_NAME, conf.CacheDir); dir != "" {
+		return dir
+	}
+	return proxy.DefaultCacheDir()
+}
+
+func storePath(ctx *cli.Context, conf *config) string {
+	if path := stringOption(ctx, STORE_NAME, conf.Store); path != "" {
+		return path
+	}
+	return proxy.DefaultStorePath()
+}
+
+// location is the time zone from --tz or the config file, for flags parsed
+// before the proxy is built.
+func location(ctx *cli.Context) (*time.Location, error) {
+	conf, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return timeZone(ctx, conf)
+}
+
+func timeZone(ctx *cli.Context, conf *config) (*time.Location, error) {
+	name := stringOption(ctx, TZ_NAME, conf.TimeZone)
+	if name == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, usageErrorf("Invalid time zone '%s': %v", name, err)
+	}
+	return location, nil
+}
@@ -17,36 +17,165 @@
 package main
 
 import (
+	"os"
+	"strings"
+
 	"github.com/sbuckfelder/github-monitoring-tool/proxy"
+	"github.com/sbuckfelder/github-monitoring-tool/render"
 	"github.com/urfave/cli"
 )
 
+const (
+	COLUMNS_NAME        string = "columns"
+	NO_HEADER_NAME      string = "no-header"
+	AUTHOR_NAME         string = "author"
+	EXCLUDE_AUTHOR_NAME string = "exclude-author"
+	EXCLUDE_BOTS_NAME   string = "exclude-bots"
+	LABEL_NAME          string = "label"
+	DRAFT_NAME          string = "draft"
+	BASE_NAME           string = "base"
+	MIN_DAYS_NAME       string = "min-days"
+	MAX_DAYS_NAME       string = "max-days"
+	CREATED_BEFORE_NAME string = "created-before"
+	CREATED_AFTER_NAME  string = "created-after"
+)
+
+var prFilterFlags = []cli.Flag{
+	cli.StringSliceFlag{
+		Name:  AUTHOR_NAME,
+		Usage: "only pull requests opened by these authors, bots included e.g. dependabot[bot]",
+	},
+	cli.StringSliceFlag{
+		Name:  EXCLUDE_AUTHOR_NAME,
+		Usage: "skip pull requests opened by these authors",
+	},
+	cli.BoolFlag{
+		Name:  EXCLUDE_BOTS_NAME,
+		Usage: "skip pull requests opened by bot accounts",
+	},
+	cli.StringSliceFlag{
+		Name:  LABEL_NAME,
+		Usage: "only pull requests with one of these labels",
+	},
+	cli.StringFlag{
+		Name:  DRAFT_NAME,
+		Usage: "only draft or only ready for review pull requests one of [draft,ready]",
+	},
+	cli.StringFlag{
+		Name:  BASE_NAME,
+		Usage: "only pull requests targeting this base branch",
+	},
+	cli.IntFlag{
+		Name:  MIN_DAYS_NAME,
+		Usage: "only pull requests with at least this many days since the last action",
+	},
+	cli.IntFlag{
+		Name:  MAX_DAYS_NAME,
+		Usage: "only pull requests with at most this many days since the last action",
+	},
+	cli.StringFlag{
+		Name:  CREATED_BEFORE_NAME,
+		Usage: "only pull requests created before this date, YYYY-MM-DD or RFC3339",
+	},
+	cli.StringFlag{
+		Name:  CREATED_AFTER_NAME,
+		Usage: "only pull requests created after this date, YYYY-MM-DD or RFC3339",
+	},
+}
+
+func prFilterFromContext(ctx *cli.Context) (proxy.PullRequestFilter, error) {
+	filter := proxy.NewPullRequestFilter()
+	filter.Authors = listOption(ctx, AUTHOR_NAME)
+	filter.ExcludeAuthors = listOption(ctx, EXCLUDE_AUTHOR_NAME)
+	filter.ExcludeBots = ctx.Bool(EXCLUDE_BOTS_NAME)
+	filter.Labels = listOption(ctx, LABEL_NAME)
+	filter.Draft = ctx.String(DRAFT_NAME)
+	filter.Base = ctx.String(BASE_NAME)
+	if ctx.IsSet(MIN_DAYS_NAME) {
+		filter.MinDays = ctx.Int(MIN_DAYS_NAME)
+	}
+	if ctx.IsSet(MAX_DAYS_NAME) {
+		filter.MaxDays = ctx.Int(MAX_DAYS_NAME)
+	}
+	loc, err := location(ctx)
+	if err != nil {
+		return filter, err
+	}
+	if filter.CreatedBefore, err = dateOption(ctx, CREATED_BEFORE_NAME, loc); err != nil {
+		return filter, err
+	}
+	if filter.CreatedAfter, err = dateOption(ctx, CREATED_AFTER_NAME, loc); err != nil {
+		return filter, err
+	}
+	if err := filter.Validate(); err != nil {
+		return filter, &usageError{msg: err.Error()}
+	}
+	return filter, nil
+}
+
 var prCommand = cli.Command{
 	Name:  "pr",
 	Usage: "List open pull requests events for a github org/repo.  Meant to identify old/stale ps's for triage.",
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		cli.StringFlag{
 			Name:     ORG_NAME,
 			Usage:    "github org repo belongs to",
 			Required: true,
 		},
+		cli.BoolFlag{
+			Name:     REPOALL_NAME,
+			Usage:    "list pull requests for all repos in an organization, cannot be used with repo flag",
+			Required: false,
+		},
 		cli.StringFlag{
 			Name:     REPO_NAME,
-			Usage:    "github repo to list events for, cannot be used with repoall flag",
-			Required: true,
+			Usage:    "github repo to list pull requests for, cannot be used with repoall flag",
+			Required: false,
 		},
-	},
+		cli.StringSliceFlag{
+			Name:  COLUMNS_NAME,
+			Usage: "columns to output in order, from [" + strings.Join(render.PullRequestColumnNames(), ",") + "], CI and mergeability columns only when listed",
+		},
+		cli.BoolFlag{
+			Name:  NO_HEADER_NAME,
+			Usage: "do not write the header row",
+		},
+	}, append(repoFilterFlags, prFilterFlags...)...),
 	Action: func(ctx *cli.Context) error {
 		orgInput := ctx.String(ORG_NAME)
-		repoInput := ctx.String(REPO_NAME)
 
-		ghProxy, err := proxy.NewProxy()
+		if err := validateRepoFlags(ctx); err != nil {
+			return err
+		}
+
+		filter, err := prFilterFromContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		columns, err := render.SelectPullRequestColumns(listOption(ctx, COLUMNS_NAME))
 		if err != nil {
-			panic("Failed to create GitHub client")
+			return &usageError{msg: err.Error()}
 		}
 
-		ghProxy.GetPullRequests(orgInput, repoInput)
+		ghProxy, err := newProxy(ctx)
+		if err != nil {
+			return err
+		}
+
+		repos, err := resolveRepos(ctx, ghProxy)
+		if err != nil {
+			return err
+		}
+
+		report, err := ghProxy.GetPullRequests(orgInput, repos, filter, render.PullRequestDetails(columns))
+		if err != nil {
+			return err
+		}
 
-		return nil
+		return render.PullRequestReport(os.Stdout, report, render.PullRequestOptions{
+			Columns:  columns,
+			NoHeader: ctx.Bool(NO_HEADER_NAME),
+		})
 	},
 }
@@ -0,0 +1,124 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/sbuckfelder/github-monitoring-tool/proxy"
+	"github.com/sbuckfelder/github-monitoring-tool/render"
+	"github.com/urfave/cli"
+)
+
+const (
+	THRESHOLD_NAME       string = "threshold"
+	MAINTAINER_TEAM_NAME string = "maintainer-team"
+)
+
+var maintainerTeamFlag = cli.StringSliceFlag{
+	Name:  MAINTAINER_TEAM_NAME,
+	Usage: "count members of this team as maintainers, as org/team or a team slug of the org",
+}
+
+var slaCommand = cli.Command{
+	Name:  "sla",
+	Usage: "Report open issues and pull requests without a timely first response from a maintainer",
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:     ORG_NAME,
+			Usage:    "github org repo belongs to",
+			Required: true,
+		},
+		cli.BoolFlag{
+			Name:     REPOALL_NAME,
+			Usage:    "report on all repos in an organization, cannot be used with repo flag",
+			Required: false,
+		},
+		cli.StringFlag{
+			Name:     REPO_NAME,
+			Usage:    "github repo to report on, cannot be used with repoall flag",
+			Required: false,
+		},
+		cli.StringFlag{
+			Name:  THRESHOLD_NAME,
+			Usage: "time allowed for a first maintainer response e.g. 72h, 3d or 2w (default 72h)",
+		},
+		maintainerTeamFlag,
+		cli.StringFlag{
+			Name:  OUTPUT_NAME,
+			Usage: "output format one of [" + strings.Join(render.ResponseFormats(), ",") + "]",
+			Value: render.DEFAULT_FORMAT,
+		},
+	}, repoFilterFlags...),
+	Action: func(ctx *cli.Context) error {
+		orgInput := ctx.String(ORG_NAME)
+
+		if err := validateRepoFlags(ctx); err != nil {
+			return err
+		}
+
+		renderer, err := render.NewResponseRenderer(ctx.String(OUTPUT_NAME))
+		if err != nil {
+			return &usageError{msg: err.Error()}
+		}
+
+		conf, err := loadConfig(ctx)
+		if err != nil {
+			return err
+		}
+
+		threshold := ctx.String(THRESHOLD_NAME)
+		if threshold == "" {
+			threshold = conf.ResponseThreshold
+		}
+		opts := proxy.ResponseOptions{
+			Maintainers: maintainerOptions(ctx, conf),
+		}
+		if threshold != "" {
+			opts.Threshold, err = proxy.ParseDuration(threshold)
+			if err != nil {
+				return usageErrorf("Invalid threshold '%s' e.g. 72h, 3d or 2w", threshold)
+			}
+		}
+
+		ghProxy, err := newProxy(ctx)
+		if err != nil {
+			return err
+		}
+
+		repos, err := resolveRepos(ctx, ghProxy)
+		if err != nil {
+			return err
+		}
+
+		report, err := ghProxy.GetResponseReport(orgInput, repos, opts)
+		if err != nil {
+			return err
+		}
+
+		return renderer.RenderResponses(os.Stdout, report)
+	},
+}
+
+func maintainerOptions(ctx *cli.Context, conf *config) proxy.MaintainerOptions {
+	teams := listOption(ctx, MAINTAINER_TEAM_NAME)
+	if len(teams) == 0 {
+		teams = conf.MaintainerTeams
+	}
+	return proxy.MaintainerOptions{Teams: teams}
+}
@@ -0,0 +1,123 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sbuckfelder/github-monitoring-tool/proxy"
+	"github.com/urfave/cli"
+)
+
+var syncCommand = cli.Command{
+	Name:  "sync",
+	Usage: "Pull new events into the local event store, for the given org or the configured sync_repos",
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:  ORG_NAME,
+			Usage: "github org to sync, sync_repos from the config file when not set",
+		},
+		cli.BoolFlag{
+			Name:  REPOALL_NAME,
+			Usage: "sync all repos in the organization, cannot be used with repo flag",
+		},
+		cli.StringFlag{
+			Name:  REPO_NAME,
+			Usage: "github repo to sync, cannot be used with repoall flag",
+		},
+	}, repoFilterFlags...),
+	Action: func(ctx *cli.Context) error {
+		orgRepos, err := syncRepos(ctx)
+		if err != nil {
+			return err
+		}
+
+		ghProxy, err := newProxy(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, org := range orgRepos.orgs {
+			repos := orgRepos.repos[org]
+			if repos == nil {
+				repos, err = resolveRepos(ctx, ghProxy)
+				if err != nil {
+					return err
+				}
+			}
+			report, err := ghProxy.Sync(org, repos)
+			if err != nil {
+				return err
+			}
+			printSyncReport(report)
+		}
+		return nil
+	},
+}
+
+type orgRepos struct {
+	orgs  []string
+	repos map[string][]string
+}
+
+// syncRepos groups the repos to sync by org, in the order given. A nil repo
+// list means the org's repos come from the repo flags.
+func syncRepos(ctx *cli.Context) (*orgRepos, error) {
+	result := &orgRepos{repos: make(map[string][]string)}
+	if org := ctx.String(ORG_NAME); org != "" {
+		if err := validateRepoFlags(ctx); err != nil {
+			return nil, err
+		}
+		result.orgs = []string{org}
+		return result, nil
+	}
+	conf, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(conf.SyncRepos) == 0 {
+		return nil, usageErrorf("Either 'org' or sync_repos in the config file needs to be set")
+	}
+	for _, entry := range conf.SyncRepos {
+		parts := strings.Split(entry, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, usageErrorf("Invalid sync_repos entry '%s' must be org/repo", entry)
+		}
+		if _, ok := result.repos[parts[0]]; !ok {
+			result.orgs = append(result.orgs, parts[0])
+		}
+		result.repos[parts[0]] = append(result.repos[parts[0]], parts[1])
+	}
+	return result, nil
+}
+
+func printSyncReport(report *proxy.SyncReport) {
+	for _, repo := range report.Repos {
+		fmt.Printf("%s/%s: %d new events\n", report.Org, repo.Repo, repo.Added)
+		if repo.First && repo.CoveredSince != nil {
+			fmt.Printf("%s/%s: history starts at %s\n",
+				report.Org, repo.Repo, repo.CoveredSince.Format(time.RFC3339))
+		}
+		if repo.Gap {
+			fmt.Fprintf(os.Stderr, "warning: %s/%s events from the last sync until %s may be missing\n",
+				report.Org, repo.Repo, repo.CoveredSince.Format(time.RFC3339))
+		}
+	}
+}
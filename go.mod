@@ -6,6 +6,7 @@ require github.com/urfave/cli v1.22.10
 
 require (
 	github.com/google/go-github/v48 v48.2.0
+	go.etcd.io/bbolt v1.3.7
 	golang.org/x/oauth2 v0.0.0-20180821212333-d2e6202438be
 )
 
@@ -17,5 +18,6 @@ require (
 	github.com/shurcooL/sanitized_anchor_name v1.0.0 // indirect
 	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5 // indirect
 	golang.org/x/net v0.0.0-20210226172049-e18ecbb05110 // indirect
+	golang.org/x/sys v0.10.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 )
@@ -0,0 +1,192 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+var (
+	APP_JWT_LIFETIME         time.Duration = 9 * time.Minute
+	APP_TOKEN_REFRESH_MARGIN time.Duration = 5 * time.Minute
+)
+
+type AppOptions struct {
+	AppID          int64
+	InstallationID int64
+	PrivateKeyFile string
+}
+
+func (o AppOptions) Enabled() bool {
+	return o.AppID != 0
+}
+
+type appTokenSource struct {
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	baseURL        string
+	client         *http.Client
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+type installationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func newAppTokenSource(opts AppOptions, baseURL string, httpClient *http.Client) (*appTokenSource, error) {
+	if opts.InstallationID == 0 {
+		return nil, &MissingTokenError{Source: "GitHub App", Err: errors.New("installation id is not set")}
+	}
+	pemBytes, err := ioutil.ReadFile(opts.PrivateKeyFile)
+	if err != nil {
+		return nil, &MissingTokenError{Source: opts.PrivateKeyFile, Err: err}
+	}
+	key, err := parsePrivateKey(pemBytes)
+	if err != nil {
+		return nil, &MissingTokenError{Source: opts.PrivateKeyFile, Err: err}
+	}
+	return &appTokenSource{
+		appID:          opts.AppID,
+		installationID: opts.InstallationID,
+		key:            key,
+		baseURL:        baseURL,
+		client:         httpClient,
+	}, nil
+}
+
+func parsePrivateKey(pemBytes []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return key, nil
+}
+
+func (s *appTokenSource) source() string {
+	return fmt.Sprintf("GitHub App %d installation %d", s.appID, s.installationID)
+}
+
+// Token returns the cached installation token, exchanging a freshly signed
+// JWT for a new one once the cached token is within the refresh margin.
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != nil && time.Until(s.token.Expiry) > 0 {
+		return s.token, nil
+	}
+	installToken, err := s.exchange()
+	if err != nil {
+		return nil, err
+	}
+	s.token = &oauth2.Token{
+		AccessToken: installToken.Token,
+		TokenType:   "token",
+		Expiry:      installToken.ExpiresAt.Add(-APP_TOKEN_REFRESH_MARGIN),
+	}
+	return s.token, nil
+}
+
+func (s *appTokenSource) exchange() (*installationToken, error) {
+	op := fmt.Sprintf("creating installation token for %s", s.source())
+	jwt, err := s.signJWT(time.Now())
+	if err != nil {
+		return nil, &APIError{Op: op, Err: err}
+	}
+	url := fmt.Sprintf("%sapp/installations/%d/access_tokens", s.baseURL, s.installationID)
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return nil, &APIError{Op: op, Err: err}
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, &APIError{Op: op, Err: err}
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &APIError{Op: op, Err: err}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &NotFoundError{Op: op, Err: errors.New(string(body))}
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return nil, &APIError{Op: op, Err: fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))}
+	}
+	var installToken installationToken
+	if err := json.Unmarshal(body, &installToken); err != nil {
+		return nil, &APIError{Op: op, Err: err}
+	}
+	return &installToken, nil
+}
+
+func (s *appTokenSource) signJWT(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	// iat is backdated to allow for clock drift between us and GitHub.
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(APP_JWT_LIFETIME).Unix(),
+		"iss": strconv.FormatInt(s.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
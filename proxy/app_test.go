@@ -0,0 +1,153 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestAppTokenSource(t *testing.T, baseURL string) *appTokenSource {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "app.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := ioutil.WriteFile(keyFile, pemBytes, 0600); err != nil {
+		t.Fatal(err)
+	}
+	source, err := newAppTokenSource(AppOptions{AppID: 42, InstallationID: 7, PrivateKeyFile: keyFile}, baseURL, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return source
+}
+
+func verifyJWT(t *testing.T, key *rsa.PublicKey, jwt string) map[string]interface{} {
+	t.Helper()
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("JWT has %d parts, want 3", len(parts))
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		t.Fatalf("JWT signature does not verify: %v", err)
+	}
+	claimBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(claimBytes, &claims); err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
+
+func TestAppSignJWT(t *testing.T) {
+	source := newTestAppTokenSource(t, "https://api.github.com/")
+	now := time.Unix(1700000000, 0)
+	jwt, err := source.signJWT(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := verifyJWT(t, &source.key.PublicKey, jwt)
+	if claims["iss"] != "42" {
+		t.Errorf("iss = %v, want 42", claims["iss"])
+	}
+	if iat := int64(claims["iat"].(float64)); iat != now.Add(-time.Minute).Unix() {
+		t.Errorf("iat = %d, want a minute before now", iat)
+	}
+	if exp := int64(claims["exp"].(float64)); exp != now.Add(APP_JWT_LIFETIME).Unix() {
+		t.Errorf("exp = %d, want %d", exp, now.Add(APP_JWT_LIFETIME).Unix())
+	}
+}
+
+func TestAppTokenExchangeAndRefresh(t *testing.T) {
+	var source *appTokenSource
+	exchanges := 0
+	expiresIn := time.Hour
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/app/installations/7/access_tokens" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		verifyJWT(t, &source.key.PublicKey, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		exchanges++
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token":"token-%d","expires_at":%q}`, exchanges, time.Now().Add(expiresIn).Format(time.RFC3339))
+	}))
+	defer server.Close()
+	source = newTestAppTokenSource(t, server.URL+"/")
+
+	for i := 0; i < 2; i++ {
+		token, err := source.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.AccessToken != "token-1" {
+			t.Errorf("token = %s, want the cached token-1", token.AccessToken)
+		}
+	}
+
+	// A token inside the refresh margin is exchanged on its next use.
+	source.token = nil
+	expiresIn = APP_TOKEN_REFRESH_MARGIN - time.Minute
+	if _, err := source.Token(); err != nil {
+		t.Fatal(err)
+	}
+	token, err := source.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "token-3" || exchanges != 3 {
+		t.Errorf("token = %s after %d exchanges, want token-3 after 3", token.AccessToken, exchanges)
+	}
+}
+
+func TestAppTokenExchangeNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"Not Found"}`)
+	}))
+	defer server.Close()
+	source := newTestAppTokenSource(t, server.URL+"/")
+	var notFound *NotFoundError
+	if _, err := source.Token(); !errors.As(err, &notFound) {
+		t.Errorf("err = %v, want a NotFoundError", err)
+	}
+}
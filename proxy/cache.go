@@ -0,0 +1,220 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const CACHE_FILE_SUFFIX string = ".json"
+
+// CACHE_MAX_AGE is how long an entry is kept without being used. Entries are
+// rewritten whenever they are revalidated, so their modification time is
+// the last time they were used.
+var CACHE_MAX_AGE time.Duration = 7 * 24 * time.Hour
+
+// cacheTransport revalidates GET requests against responses stored on disk
+// with their ETag/Last-Modified and serves 304 Not Modified responses from
+// the stored copy, which GitHub does not count against the rate limit.
+type cacheTransport struct {
+	base http.RoundTripper
+	dir  string
+	// identity names credentials whose tokens rotate, such as a GitHub App
+	// installation, so entries outlive each token. Without it entries are
+	// keyed by the Authorization header.
+	identity string
+}
+
+type cacheEntry struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+func DefaultCacheDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "ghmt")
+}
+
+func ClearCache(dir string) error {
+	return pruneCache(dir, time.Time{})
+}
+
+// pruneCache removes the entries, and temporary files left by interrupted
+// writes, last modified before cutoff, or all of them for the zero time.
+func pruneCache(dir string, cutoff time.Time) error {
+	var files []string
+	for _, pattern := range []string{"*" + CACHE_FILE_SUFFIX, "tmp-*"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return err
+		}
+		files = append(files, matches...)
+	}
+	for _, file := range files {
+		if !cutoff.IsZero() {
+			info, err := os.Stat(file)
+			if err != nil || !info.ModTime().Before(cutoff) {
+				continue
+			}
+		}
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// newCacheTransport prunes the entries unused for CACHE_MAX_AGE, a failure
+// to prune only leaves them for the next run.
+func newCacheTransport(base http.RoundTripper, dir string) (*cacheTransport, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	pruneCache(dir, time.Now().Add(-1*CACHE_MAX_AGE))
+	return &cacheTransport{base: base, dir: dir}, nil
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.base.RoundTrip(req)
+	}
+	fileName := t.fileName(req)
+	entry := t.load(fileName)
+	if entry != nil {
+		req = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close()
+		entry.update(resp.Header)
+		t.store(fileName, entry)
+		return entry.response(req), nil
+	}
+	if resp.StatusCode != http.StatusOK ||
+		(resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "") {
+		return resp, nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	t.store(fileName, &cacheEntry{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	})
+	return resp, nil
+}
+
+// fileName keys entries by URL, Accept header and credentials. The same URL
+// can return different representations for different media types, and
+// different data such as X-OAuth-Scopes for different tokens. Only the hash
+// of the credentials ends up in the file name.
+func (t *cacheTransport) fileName(req *http.Request) string {
+	credentials := t.identity
+	if credentials == "" {
+		credentials = req.Header.Get("Authorization")
+	}
+	sum := sha256.Sum256([]byte(req.URL.String() + "\n" + req.Header.Get("Accept") + "\n" + credentials))
+	return filepath.Join(t.dir, hex.EncodeToString(sum[:])+CACHE_FILE_SUFFIX)
+}
+
+func (t *cacheTransport) load(fileName string) *cacheEntry {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil
+	}
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil
+	}
+	return entry
+}
+
+// store writes through a temporary file so concurrent workers never read a
+// partially written entry. Failing to cache is not an error for the request.
+func (t *cacheTransport) store(fileName string, entry *cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	tmp, err := ioutil.TempFile(t.dir, "tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	closeErr := tmp.Close()
+	if err != nil || closeErr != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), fileName); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// update merges the headers of a 304 into the stored ones, as RFC 7234
+// section 4.3.4 describes, so the client sees the current rate limit budget
+// and any other header the server refreshed.
+func (e *cacheEntry) update(fresh http.Header) {
+	for key, values := range fresh {
+		if key == "Content-Length" {
+			continue
+		}
+		e.Header[key] = values
+	}
+}
+
+// response rebuilds the stored response.
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	header := e.Header.Clone()
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,146 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheTransportRevalidates(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-OAuth-Scopes", "repo")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.Header().Set("X-Ratelimit-Remaining", "41")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("X-Ratelimit-Remaining", "42")
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+	transport, err := newCacheTransport(http.DefaultTransport, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: transport}
+	get := func() *http.Response {
+		t.Helper()
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	get().Body.Close()
+	resp := get()
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "body" {
+		t.Errorf("got %d %q, want the cached 200 body", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("X-Ratelimit-Remaining"); got != "41" {
+		t.Errorf("X-Ratelimit-Remaining %q, want the value from the 304", got)
+	}
+	if requests != 2 {
+		t.Errorf("server saw %d requests, want 2", requests)
+	}
+}
+
+func TestCacheTransportMergesNotModifiedHeaders(t *testing.T) {
+	scopes := "repo"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-OAuth-Scopes", scopes)
+		if r.Header.Get("If-None-Match") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+	transport, err := newCacheTransport(http.DefaultTransport, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: transport}
+	for _, want := range []string{"repo", "read:org"} {
+		scopes = want
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if got := resp.Header.Get("X-OAuth-Scopes"); got != want {
+			t.Errorf("X-OAuth-Scopes %q, want %q", got, want)
+		}
+	}
+}
+
+func TestCacheTransportKeysByAuthorization(t *testing.T) {
+	transport := &cacheTransport{dir: t.TempDir()}
+	first, _ := http.NewRequest(http.MethodGet, "https://api.github.com/user", nil)
+	first.Header.Set("Authorization", "Bearer one")
+	second := first.Clone(first.Context())
+	second.Header.Set("Authorization", "Bearer two")
+	if transport.fileName(first) == transport.fileName(second) {
+		t.Error("requests with different tokens share a cache entry")
+	}
+}
+
+func TestCacheTransportKeysByIdentity(t *testing.T) {
+	transport := &cacheTransport{dir: t.TempDir(), identity: "GitHub App 1 installation 2"}
+	first, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/o/r/events", nil)
+	first.Header.Set("Authorization", "Bearer ghs_one")
+	second := first.Clone(first.Context())
+	second.Header.Set("Authorization", "Bearer ghs_two")
+	if transport.fileName(first) != transport.fileName(second) {
+		t.Error("rotated installation tokens do not share a cache entry")
+	}
+}
+
+func TestNewCacheTransportPrunesUnusedEntries(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-1*CACHE_MAX_AGE - time.Hour)
+	for _, name := range []string{"stale" + CACHE_FILE_SUFFIX, "tmp-1", "fresh" + CACHE_FILE_SUFFIX} {
+		file := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(file, []byte("{}"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if name != "fresh"+CACHE_FILE_SUFFIX {
+			if err := os.Chtimes(file, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if _, err := newCacheTransport(http.DefaultTransport, dir); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != 1 || filepath.Base(files[0]) != "fresh"+CACHE_FILE_SUFFIX {
+		t.Errorf("cache holds %v after pruning, want only the fresh entry", files)
+	}
+}
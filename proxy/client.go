@@ -19,49 +19,224 @@ package proxy
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
-	"os"
+	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v48/github"
 	"golang.org/x/oauth2"
 )
 
 var (
-	tokenFile = ".ghmt"
+	DEFAULT_API_URL string = "https://api.github.com/"
+	DEFAULT_WEB_URL string = "https://github.com"
 )
 
+type Options struct {
+	Token       TokenOptions
+	App         AppOptions
+	APIURL      string
+	WebURL      string
+	Concurrency int
+	CacheDir    string
+	// Location governs calendar date boundaries, timestamps without an
+	// offset and the times in event reports. Defaults to UTC.
+	Location *time.Location
+	// StorePath is the local event store used by Sync and
+	// GetEventsFromStore.
+	StorePath string
+}
+
 type GithubProxy struct {
-	client github.Client
+	client       *github.Client
+	webURL       string
+	tokenSource  string
+	installation bool
+	concurrency  int
+	location     *time.Location
+	storePath    string
+	budget       *rateBudget
+}
+
+type AuthStatus struct {
+	Source string
+	Login  string
+	Scopes []string
 }
 
-func NewProxy() (GithubProxy, error) {
+func NewProxy(opts Options) (*GithubProxy, error) {
 	ctx := context.Background()
-	token, err := getToken()
+	apiURL, err := apiBaseURL(opts.APIURL)
+	if err != nil {
+		return nil, err
+	}
+	webURL, err := webBaseURL(opts.WebURL, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Token.Host == "" {
+		opts.Token.Host = webURL.Host
+	}
+	var transport http.RoundTripper = newRetryTransport(http.DefaultTransport)
+	var cache *cacheTransport
+	if opts.CacheDir != "" {
+		cache, err = newCacheTransport(transport, opts.CacheDir)
+		if err != nil {
+			return nil, err
+		}
+		transport = cache
+	}
+	budget := &rateBudget{}
+	baseClient := &http.Client{Transport: &rateTransport{base: transport, budget: budget}}
+	tokenSource, sourceName, err := newTokenSource(opts, apiURL, baseClient)
+	if err != nil {
+		return nil, err
+	}
+	// Installation tokens expire every hour, the installation they belong to
+	// is what the cached responses depend on.
+	if cache != nil && opts.App.Enabled() {
+		cache.identity = sourceName
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, baseClient)
+	client, err := newGithubClient(apiURL, oauth2.NewClient(ctx, tokenSource))
 	if err != nil {
-		panicMsg := fmt.Sprintf("Failed to get token from %s: %v", getTokenFileName(), err)
-		panic(panicMsg)
+		return nil, err
+	}
+	proxy := newGithubProxy(opts, client, webURL, budget)
+	proxy.tokenSource = sourceName
+	proxy.installation = opts.App.Enabled()
+	return proxy, nil
+}
+
+// NewOfflineProxy needs no credentials. Its client is unauthenticated, so it
+// is meant for reports from the local event store.
+func NewOfflineProxy(opts Options) (*GithubProxy, error) {
+	apiURL, err := apiBaseURL(opts.APIURL)
+	if err != nil {
+		return nil, err
+	}
+	webURL, err := webBaseURL(opts.WebURL, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	budget := &rateBudget{}
+	transport := &rateTransport{base: newRetryTransport(http.DefaultTransport), budget: budget}
+	client, err := newGithubClient(apiURL, &http.Client{Transport: transport})
+	if err != nil {
+		return nil, err
+	}
+	return newGithubProxy(opts, client, webURL, budget), nil
+}
+
+func newGithubClient(apiURL *url.URL, httpClient *http.Client) (*github.Client, error) {
+	if apiURL.String() == DEFAULT_API_URL {
+		return github.NewClient(httpClient), nil
+	}
+	uploadURL := url.URL{Scheme: apiURL.Scheme, Host: apiURL.Host, Path: "/"}
+	return github.NewEnterpriseClient(apiURL.String(), uploadURL.String(), httpClient)
+}
+
+func newGithubProxy(opts Options, client *github.Client, webURL *url.URL, budget *rateBudget) *GithubProxy {
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = DEFAULT_CONCURRENCY
+	}
+	location := opts.Location
+	if location == nil {
+		location = time.UTC
+	}
+	return &GithubProxy{
+		client:      client,
+		webURL:      strings.TrimSuffix(webURL.String(), "/"),
+		concurrency: concurrency,
+		location:    location,
+		storePath:   opts.StorePath,
+		budget:      budget}
+}
+
+// apiBaseURL normalizes a GitHub API URL the same way
+// github.NewEnterpriseClient does, so a bare GitHub Enterprise Server host
+// gets its /api/v3/ path.
+func apiBaseURL(apiURL string) (*url.URL, error) {
+	if apiURL == "" {
+		apiURL = DEFAULT_API_URL
+	}
+	base, err := url.Parse(apiURL)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("Invalid API URL '%s'", apiURL)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	if !strings.HasSuffix(base.Path, "/api/v3/") &&
+		!strings.HasPrefix(base.Host, "api.") &&
+		!strings.Contains(base.Host, ".api.") {
+		base.Path += "api/v3/"
+	}
+	return base, nil
+}
+
+func webBaseURL(webURL string, apiURL *url.URL) (*url.URL, error) {
+	if webURL == "" {
+		if apiURL.String() == DEFAULT_API_URL {
+			webURL = DEFAULT_WEB_URL
+		} else {
+			webURL = apiURL.Scheme + "://" + strings.TrimPrefix(apiURL.Host, "api.")
+		}
+	}
+	base, err := url.Parse(webURL)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("Invalid web URL '%s'", webURL)
+	}
+	return base, nil
+}
+
+func newTokenSource(opts Options, apiURL *url.URL, httpClient *http.Client) (oauth2.TokenSource, string, error) {
+	if opts.App.Enabled() {
+		appSource, err := newAppTokenSource(opts.App, apiURL.String(), httpClient)
+		if err != nil {
+			return nil, "", err
+		}
+		return appSource, appSource.source(), nil
+	}
+	token, err := ResolveToken(opts.Token)
+	if err != nil {
+		return nil, "", err
 	}
 	tokenSource := oauth2.StaticTokenSource(
 		&oauth2.Token{
-			AccessToken: token},
+			AccessToken: token.Value},
 	)
-	tcpClient := oauth2.NewClient(ctx, tokenSource)
-	client := github.NewClient(tcpClient)
-	return GithubProxy{
-		client: *client}, nil
-}
-
-func getTokenFileName() string {
-	homeDir, _ := os.UserHomeDir()
-	return homeDir + "/" + tokenFile
+	return tokenSource, token.Source, nil
 }
 
-func getToken() (string, error) {
-	token, err := ioutil.ReadFile(getTokenFileName())
+func (p *GithubProxy) GetAuthStatus() (*AuthStatus, error) {
+	ctx := context.Background()
+	if p.installation {
+		// Installation tokens carry permissions rather than OAuth scopes and
+		// cannot look up an authenticated user.
+		if _, _, err := p.client.Apps.ListRepos(ctx, &github.ListOptions{PerPage: 1}); err != nil {
+			return nil, wrapAPIError(err, "listing installation repositories")
+		}
+		return &AuthStatus{Source: p.tokenSource}, nil
+	}
+	user, resp, err := p.client.Users.Get(ctx, "")
 	if err != nil {
-		return "", err
+		return nil, wrapAPIError(err, "getting authenticated user")
+	}
+	status := &AuthStatus{
+		Source: p.tokenSource,
+		Login:  user.GetLogin(),
 	}
-	cleanToken := strings.Replace(string(token), "\n", "", -1)
-	return cleanToken, nil
+	for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			status.Scopes = append(status.Scopes, scope)
+		}
+	}
+	return status, nil
+}
+
+func (p *GithubProxy) repoURL(org, repo string) string {
+	return fmt.Sprintf("%s/%s/%s", p.webURL, org, repo)
 }
@@ -0,0 +1,119 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+)
+
+type MissingTokenError struct {
+	Source string
+	Err    error
+}
+
+func (e *MissingTokenError) Error() string {
+	return fmt.Sprintf("Failed to get token from %s: %v", e.Source, e.Err)
+}
+
+func (e *MissingTokenError) Unwrap() error {
+	return e.Err
+}
+
+type InvalidTimeError struct {
+	Value  string
+	Format string
+	Err    error
+}
+
+func (e *InvalidTimeError) Error() string {
+	return fmt.Sprintf("Time value:'%s' not in %s", e.Value, e.Format)
+}
+
+func (e *InvalidTimeError) Unwrap() error {
+	return e.Err
+}
+
+type APIError struct {
+	Op  string
+	Err error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Failed %s: %v", e.Op, e.Err)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+type NotFoundError struct {
+	Op  string
+	Err error
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("Failed %s: not found", e.Op)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+type RateLimitedError struct {
+	Op    string
+	Reset time.Time
+	Err   error
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.Reset.IsZero() {
+		return fmt.Sprintf("Failed %s: rate limited", e.Op)
+	}
+	return fmt.Sprintf("Failed %s: rate limited until %s", e.Op, e.Reset.Format(time.RFC3339))
+}
+
+func (e *RateLimitedError) Unwrap() error {
+	return e.Err
+}
+
+func wrapAPIError(err error, op string) error {
+	if err == nil {
+		return nil
+	}
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		return &RateLimitedError{Op: op, Reset: rateErr.Rate.Reset.Time, Err: err}
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		reset := time.Time{}
+		if abuseErr.RetryAfter != nil {
+			reset = time.Now().Add(*abuseErr.RetryAfter)
+		}
+		return &RateLimitedError{Op: op, Reset: reset, Err: err}
+	}
+	var respErr *github.ErrorResponse
+	if errors.As(err, &respErr) && respErr.Response != nil && respErr.Response.StatusCode == http.StatusNotFound {
+		return &NotFoundError{Op: op, Err: err}
+	}
+	return &APIError{Op: op, Err: err}
+}
@@ -0,0 +1,94 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v48/github"
+)
+
+// Event types returned by the repository events API.
+var EVENT_TYPES = []string{
+	"CommitCommentEvent",
+	"CreateEvent",
+	"DeleteEvent",
+	"ForkEvent",
+	"GollumEvent",
+	"IssueCommentEvent",
+	"IssuesEvent",
+	"MemberEvent",
+	"PublicEvent",
+	"PullRequestEvent",
+	"PullRequestReviewEvent",
+	"PullRequestReviewCommentEvent",
+	"PullRequestReviewThreadEvent",
+	"PushEvent",
+	"ReleaseEvent",
+	"SponsorshipEvent",
+	"WatchEvent",
+}
+
+type EventFilter struct {
+	Types         []string
+	ExcludeTypes  []string
+	Actors        []string
+	ExcludeActors []string
+	ExcludeBots   bool
+}
+
+func (f EventFilter) Validate() error {
+	for _, eventType := range append(append([]string{}, f.Types...), f.ExcludeTypes...) {
+		if !containsAny([]string{eventType}, EVENT_TYPES) {
+			return fmt.Errorf("Unknown event type '%s' must be one of [%s]",
+				eventType, strings.Join(EVENT_TYPES, ","))
+		}
+	}
+	_, err := f.filters()
+	return err
+}
+
+// filters composes the filter into predicates for filterEvents. Actor
+// patterns accept the same globs and "re:" regular expressions as repo names.
+func (f EventFilter) filters() ([]func(*github.Event) bool, error) {
+	var filters []func(*github.Event) bool
+	if len(f.Types) != 0 {
+		filters = append(filters, eventFilterType(f.Types))
+	}
+	if len(f.ExcludeTypes) != 0 {
+		filters = append(filters, eventFilterExcludeType(f.ExcludeTypes))
+	}
+	if len(f.Actors) != 0 {
+		actors, err := newNameMatchers(f.Actors)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, eventFilterActor(actors))
+	}
+	if len(f.ExcludeActors) != 0 {
+		actors, err := newNameMatchers(f.ExcludeActors)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, eventFilterExcludeActor(actors))
+	}
+	if f.ExcludeBots {
+		filters = append(filters, eventFilterExcludeBots())
+	}
+	return filters, nil
+}
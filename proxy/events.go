@@ -19,94 +19,196 @@ package proxy
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sort"
-	"strings"
 	"time"
 
 	"github.com/google/go-github/v48/github"
 )
 
 var EVENTS_PER_PAGE int = 100
-var REPORT_SEPERATOR string = strings.Repeat("*", 20)
 
-func (p *GithubProxy) GetEventsForHours(org string, repos []string, hours int) {
-	currentTime := time.Now()
-	adjTime := currentTime.Add(time.Hour * time.Duration(-1*hours))
-	ctx := context.Background()
-	for _, repo := range repos {
-		events, _ := p.getEventsSince(ctx, org, repo, adjTime)
-		fmt.Printf("https://github.com/%s/%s Events Since %s\n", org, repo, adjTime.Format(time.RFC3339))
-		GenerateEventReport(events, repo)
-	}
-	fmt.Printf("_Based on Events from %s to %s_\n",
-		adjTime.Format(time.RFC3339),
-		currentTime.Format(time.RFC3339))
+// The repository events API only returns the latest EVENTS_API_MAX_EVENTS
+// events, none older than EVENTS_API_MAX_AGE.
+var (
+	EVENTS_API_MAX_EVENTS int           = 300
+	EVENTS_API_MAX_AGE    time.Duration = 90 * 24 * time.Hour
+)
+
+// eventSource fetches the events of one repo for a window, along with the
+// oldest time they are complete from.
+type eventSource func(ctx context.Context, org, repo string, window TimeWindow) ([]*github.Event, time.Time, error)
+
+// GetEvents reports the events of each repo that happened inside the
+// window, resolved in the proxy's location.
+func (p *GithubProxy) GetEvents(org string, repos []string, spec TimeWindowSpec, filter EventFilter) (*EventReport, error) {
+	return p.getEventReport(org, repos, spec, filter, p.getWindowEvents)
 }
 
-func (p *GithubProxy) GetEventsSinceRFC3339(org string, repos []string, sinceString string) {
-	currentTime := time.Now()
-	since, err := time.Parse(time.RFC3339, sinceString)
+// GetEventsFromSearch builds the same report as GetEvents from the search
+// API, which is not limited to the last 90 days.
+func (p *GithubProxy) GetEventsFromSearch(org string, repos []string, spec TimeWindowSpec, filter EventFilter) (*EventReport, error) {
+	return p.getEventReport(org, repos, spec, filter, p.getSearchEvents)
+}
+
+// GetEventsFillingGaps is GetEvents, except that for repos where the Events
+// API retention limit cut the window short, the missing start of the window
+// is filled from the search API.
+func (p *GithubProxy) GetEventsFillingGaps(org string, repos []string, spec TimeWindowSpec, filter EventFilter) (*EventReport, error) {
+	return p.getEventReport(org, repos, spec, filter, p.getGapFilledEvents)
+}
+
+func (p *GithubProxy) getEventReport(
+	org string,
+	repos []string,
+	spec TimeWindowSpec,
+	filter EventFilter,
+	source eventSource) (*EventReport, error) {
+	window, err := spec.Resolve(time.Now(), p.location)
+	if err != nil {
+		return nil, err
+	}
+	filters, err := filter.filters()
 	if err != nil {
-		panicMsg := fmt.Sprintf("Since value:'%s' not in RFC3339 e.g. 2006-01-02T15:04:05Z", sinceString)
-		panic(panicMsg)
+		return nil, err
 	}
 	ctx := context.Background()
-	for _, repo := range repos {
-		events, _ := p.getEventsSince(ctx, org, repo, since)
-		fmt.Printf("https://github.com/%s/%s Events Since %s\n", org, repo, sinceString)
-		GenerateEventReport(events, repo)
+	report := &EventReport{
+		Org:   org,
+		Since: window.Since,
+		Until: window.Until,
+		Date:  window.Label,
 	}
-	fmt.Printf("_Based on Events from %s to %s_\n",
-		since.Format(time.RFC3339),
-		currentTime.Format(time.RFC3339))
+	report.Repos, err = p.getRepoEventReports(ctx, org, repos, window, source,
+		append(filters, eventFilterUntil(window.Until))...)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
 }
 
-func (p *GithubProxy) GetEventsForDate(org string, repos []string, dateString string) {
-	const dateLayout = "2006-01-02"
-	targetDate, err := time.Parse(dateLayout, dateString)
+func (p *GithubProxy) getRepoEventReports(
+	ctx context.Context,
+	org string,
+	repos []string,
+	window TimeWindow,
+	source eventSource,
+	filters ...func(*github.Event) bool) ([]RepoEventReport, error) {
+	reports := make([]RepoEventReport, len(repos))
+	err := p.forEachRepo(ctx, repos, func(ctx context.Context, i int, repo string) error {
+		events, coveredSince, err := source(ctx, org, repo, window)
+		if err != nil {
+			return err
+		}
+		for _, filter := range filters {
+			events = filterEvents(events, filter)
+		}
+		reports[i] = buildRepoEventReport(repo, p.repoURL(org, repo), events)
+		if coveredSince.After(window.Since) {
+			coveredSince = coveredSince.In(window.Since.Location())
+			reports[i].Truncated = true
+			reports[i].CoveredSince = &coveredSince
+		}
+		return nil
+	})
 	if err != nil {
-		panicMsg := fmt.Sprintf("Since value:'%s' not in YYYY-MM-DD e.g. 2006-01-02", dateString)
-		panic(panicMsg)
+		return nil, err
 	}
-	ctx := context.Background()
-	for _, repo := range repos {
-		events, _ := p.getEventsSince(ctx, org, repo, targetDate)
-		events = filterEvents(events, eventFilterDate(targetDate))
-		fmt.Printf("https://github.com/%s/%s Events for %s\n", org, repo, dateString)
-		GenerateEventReport(events, repo)
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Repo < reports[j].Repo
+	})
+	return reports, nil
+}
+
+func (p *GithubProxy) getWindowEvents(ctx context.Context, org, repo string, window TimeWindow) ([]*github.Event, time.Time, error) {
+	return p.getEventsSince(ctx, org, repo, window.Since, "")
+}
+
+// getGapFilledEvents fetches the window from the Events API and rebuilds
+// [window.Since, coveredSince) from the search API when that was truncated.
+func (p *GithubProxy) getGapFilledEvents(ctx context.Context, org, repo string, window TimeWindow) ([]*github.Event, time.Time, error) {
+	events, coveredSince, err := p.getWindowEvents(ctx, org, repo, window)
+	if err != nil || !coveredSince.After(window.Since) {
+		return events, coveredSince, err
 	}
-	fmt.Printf("_Based on Events for %s\n", dateString)
+	gap := TimeWindow{Since: window.Since, Until: coveredSince}
+	gapEvents, gapCoveredSince, err := p.getSearchEvents(ctx, org, repo, gap)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	gapEvents = filterEvents(gapEvents, eventFilterUntil(coveredSince))
+	return append(events, gapEvents...), gapCoveredSince, nil
 }
 
+// getEventsSince pages back until it reaches an event before since, or one
+// no newer than the watermark event ID when that is set. When the API runs
+// out of events first, the returned time is the oldest moment the events
+// actually cover, otherwise it is since.
 func (p *GithubProxy) getEventsSince(
 	ctx context.Context,
 	org, repo string,
-	since time.Time) ([]*github.Event, error) {
+	since time.Time,
+	watermark string) ([]*github.Event, time.Time, error) {
 	pageNumber := 1
 	found := false
+	exhausted := false
 	var events []*github.Event
-	for found == false {
+	for !found && !exhausted {
 		listOpts := &github.ListOptions{
 			PerPage: EVENTS_PER_PAGE,
 			Page:    pageNumber}
 
-		newEvents, _, err := p.client.Activity.ListRepositoryEvents(ctx, org, repo, listOpts)
+		newEvents, resp, err := p.client.Activity.ListRepositoryEvents(ctx, org, repo, listOpts)
 		if err != nil {
-			return nil, err
+			// Paging past the retention limit is rejected rather than
+			// answered with an empty page.
+			if resp != nil && resp.StatusCode == http.StatusUnprocessableEntity && len(events) != 0 {
+				break
+			}
+			return nil, time.Time{}, wrapAPIError(err, fmt.Sprintf("listing events for %s/%s", org, repo))
 		}
-		if len(newEvents) == 0 {
-			found = true
-		} else {
+		if len(newEvents) != 0 {
 			lastEvent := newEvents[len(newEvents)-1]
 			if lastEvent.CreatedAt.Before(since) {
 				found = true
 			}
+			if watermark != "" && !eventIDAfter(lastEvent.GetID(), watermark) {
+				found = true
+			}
+		}
+		if len(newEvents) == 0 || resp.NextPage == 0 {
+			exhausted = true
 		}
 		events = append(events, newEvents...)
 		pageNumber++
 	}
+	coveredSince := since
+	// With a watermark an empty listing means nothing happened since the
+	// last sync, not that GitHub dropped the events in between.
+	truncated := !found && eventsTruncated(events, since)
+	if watermark != "" && len(events) == 0 {
+		truncated = false
+	}
+	if truncated {
+		coveredSince = time.Now().Add(-1 * EVENTS_API_MAX_AGE)
+		if len(events) >= EVENTS_API_MAX_EVENTS {
+			coveredSince = events[len(events)-1].GetCreatedAt()
+		}
+	}
 	events = filterEvents(events, eventFilterSince(since))
-	return events, nil
+	if watermark != "" {
+		events = filterEvents(events, eventFilterAfterID(watermark))
+	}
+	return events, coveredSince, nil
+}
+
+// eventsTruncated reports whether running out of events before reaching
+// since means GitHub dropped older events, rather than the repo having none.
+func eventsTruncated(events []*github.Event, since time.Time) bool {
+	if len(events) >= EVENTS_API_MAX_EVENTS {
+		return true
+	}
+	return since.Before(time.Now().Add(-1 * EVENTS_API_MAX_AGE))
 }
 
 func filterEvents(events []*github.Event, filter func(*github.Event) bool) []*github.Event {
@@ -121,230 +223,56 @@ func filterEvents(events []*github.Event, filter func(*github.Event) bool) []*gi
 
 func eventFilterSince(since time.Time) func(*github.Event) bool {
 	return func(event *github.Event) bool {
-		return event.CreatedAt.After(since)
+		return !event.CreatedAt.Before(since)
 	}
 }
 
-func eventFilterDate(date time.Time) func(*github.Event) bool {
+func eventFilterUntil(until time.Time) func(*github.Event) bool {
 	return func(event *github.Event) bool {
-		return event.CreatedAt.Year() == date.Year() &&
-			event.CreatedAt.Month() == date.Month() &&
-			event.CreatedAt.Day() == date.Day()
-
+		return event.CreatedAt.Before(until)
 	}
 }
 
-func eventFilterType() func(*github.Event) bool {
+func eventFilterAfterID(watermark string) func(*github.Event) bool {
 	return func(event *github.Event) bool {
-		switch *event.Type {
-		case "IssuesEvent", "IssuesCommentEvent", "PullRequestEvent", "PullRequestReviewEvent", "PullRequestReviewCommentEvent":
-			return true
-		default:
-			return false
-		}
+		return eventIDAfter(event.GetID(), watermark)
 	}
 }
 
-func printPullRequestReport(events []*github.Event, repo string) {
-	var newPRs = []*github.Event{}
-	var mergedPRs = []*github.Event{}
-	for _, event := range events {
-		payload, _ := event.ParsePayload()
-		prEvent := payload.(*github.PullRequestEvent)
-		if *prEvent.Action == "opened" {
-			newPRs = append(newPRs, event)
-		}
-		if *prEvent.Action == "closed" && *prEvent.PullRequest.Merged {
-			mergedPRs = append(mergedPRs, event)
-		}
-	}
-	if len(mergedPRs) != 0 {
-		fmt.Printf("===========\n")
-		fmt.Printf("PR'S MERGED\n")
-		fmt.Printf("===========\n")
-		var mergedPRText = sort.StringSlice{}
-		for _, prEvent := range mergedPRs {
-			mergedPRText = append(mergedPRText, printPullRequestEvent(prEvent, repo))
-		}
-		mergedPRText.Sort()
-		for _, txt := range mergedPRText {
-			fmt.Print(txt)
-		}
-	}
-	if len(newPRs) != 0 {
-		fmt.Printf("=================\n")
-		fmt.Printf("NEW PULL REQUESTS\n")
-		fmt.Printf("=================\n")
-		var newPRText = sort.StringSlice{}
-		for _, prEvent := range newPRs {
-			newPRText = append(newPRText, printPullRequestEvent(prEvent, repo))
-		}
-		newPRText.Sort()
-		for _, txt := range newPRText {
-			fmt.Print(txt)
-		}
+// eventIDAfter compares event IDs, which are increasing decimal integers.
+func eventIDAfter(id, watermark string) bool {
+	if len(id) != len(watermark) {
+		return len(id) > len(watermark)
 	}
+	return id > watermark
 }
 
-func printPullRequestEvent(event *github.Event, repo string) string {
-	payload, _ := event.ParsePayload()
-	prEvent := payload.(*github.PullRequestEvent)
-
-	return fmt.Sprintf("- **%s** PR#%d %s: [%s](%s)\n",
-		repo,
-		*prEvent.Number,
-		*prEvent.PullRequest.User.Login,
-		*prEvent.PullRequest.Title,
-		*prEvent.PullRequest.HTMLURL)
-}
-
-func getPullRequestURLFromReview(event *github.Event) (string, string) {
-	if *event.Type == "PullRequestReviewEvent" {
-		payload, _ := event.ParsePayload()
-		rev := payload.(*github.PullRequestReviewEvent)
-		return *rev.PullRequest.HTMLURL, *rev.PullRequest.Title
-	}
-	if *event.Type == "PullRequestReviewCommentEvent" {
-		payload, _ := event.ParsePayload()
-		rev := payload.(*github.PullRequestReviewCommentEvent)
-		return *rev.PullRequest.HTMLURL, *rev.PullRequest.Title
+func eventFilterType(types []string) func(*github.Event) bool {
+	return func(event *github.Event) bool {
+		return containsAny([]string{event.GetType()}, types)
 	}
-	return "", ""
 }
 
-func printPullRequestReviewReport(events []*github.Event, repo string) {
-	reviewMap := make(map[string]int)
-	titleMap := make(map[string]string)
-	for _, event := range events {
-		url, title := getPullRequestURLFromReview(event)
-		reviewMap[url] = reviewMap[url] + 1
-		titleMap[url] = title
-	}
-	fmt.Printf("==========================\n")
-	fmt.Printf("PR REVIEW/COMMENT ACTIVITY\n")
-	fmt.Printf("==========================\n")
-	var revText = sort.StringSlice{}
-	for url, val := range reviewMap {
-		revText = append(revText, fmt.Sprintf("Actions:%d - **%s** [%s](%s)\n", val, repo, titleMap[url], url))
-	}
-	revText.Sort()
-	for _, txt := range revText {
-		fmt.Print(txt)
+func eventFilterExcludeType(types []string) func(*github.Event) bool {
+	return func(event *github.Event) bool {
+		return !containsAny([]string{event.GetType()}, types)
 	}
 }
 
-func printIssueEventReport(events []*github.Event, repo string) {
-	var newIssues = []*github.Event{}
-	var closedIssues = []*github.Event{}
-	for _, event := range events {
-		payload, _ := event.ParsePayload()
-		issuesEvent := payload.(*github.IssuesEvent)
-		if *issuesEvent.Action == "opened" {
-			newIssues = append(newIssues, event)
-		}
-		if *issuesEvent.Action == "closed" {
-			closedIssues = append(closedIssues, event)
-		}
-	}
-	if len(newIssues) != 0 {
-		fmt.Printf("==========\n")
-		fmt.Printf("NEW ISSUES\n")
-		fmt.Printf("==========\n")
-		var newIssueText = sort.StringSlice{}
-		for _, issueEvent := range newIssues {
-			newIssueText = append(newIssueText, printIssueEvent(issueEvent, repo))
-		}
-		newIssueText.Sort()
-		for _, txt := range newIssueText {
-			fmt.Print(txt)
-		}
-	}
-	if len(closedIssues) != 0 {
-		fmt.Printf("=============\n")
-		fmt.Printf("CLOSED ISSUES\n")
-		fmt.Printf("=============\n")
-		var closedIssueText = sort.StringSlice{}
-		for _, issueEvent := range closedIssues {
-			closedIssueText = append(closedIssueText, printIssueEvent(issueEvent, repo))
-		}
-		closedIssueText.Sort()
-		for _, txt := range closedIssueText {
-			fmt.Print(txt)
-		}
+func eventFilterActor(actors []nameMatcher) func(*github.Event) bool {
+	return func(event *github.Event) bool {
+		return matchAny(actors, event.GetActor().GetLogin())
 	}
 }
 
-func printIssueEvent(event *github.Event, repo string) string {
-	payload, _ := event.ParsePayload()
-	issuesEvent := payload.(*github.IssuesEvent)
-	return fmt.Sprintf("- **%s** ISSUE#%d %s: [%s](%s)\n",
-		repo,
-		*issuesEvent.Issue.Number,
-		*issuesEvent.Issue.User.Login,
-		*issuesEvent.Issue.Title,
-		*issuesEvent.Issue.HTMLURL)
-}
-
-func printIssueCommentEventReport(events []*github.Event, repo string) {
-	commentMap := make(map[string]int)
-	titleMap := make(map[string]string)
-	for _, event := range events {
-		payload, _ := event.ParsePayload()
-		commentEvent := payload.(*github.IssueCommentEvent)
-		url := *commentEvent.Issue.HTMLURL
-		commentMap[url] = commentMap[url] + 1
-		titleMap[url] = *commentEvent.Issue.Title
-	}
-	fmt.Printf("======================\n")
-	fmt.Printf("ISSUE COMMENT ACTIVITY\n")
-	fmt.Printf("======================\n")
-	var commentText = sort.StringSlice{}
-	for url, val := range commentMap {
-		commentText = append(commentText, fmt.Sprintf("Comments:%d - **%s** [%s](%s)\n", val, repo, titleMap[url], url))
-	}
-	commentText.Sort()
-	for _, txt := range commentText {
-		fmt.Print(txt)
+func eventFilterExcludeActor(actors []nameMatcher) func(*github.Event) bool {
+	return func(event *github.Event) bool {
+		return !matchAny(actors, event.GetActor().GetLogin())
 	}
 }
 
-func GenerateEventReport(events []*github.Event, repo string) {
-	defer fmt.Printf("%s\n", REPORT_SEPERATOR)
-	eventMap := make(map[string][]*github.Event)
-	for _, event := range events {
-		eventList, ok := eventMap[*event.Type]
-		if !ok {
-			eventList = []*github.Event{}
-		}
-		eventList = append(eventList, event)
-		eventMap[*event.Type] = eventList
-	}
-	if len(eventMap) == 0 {
-		fmt.Printf("No Events\n")
-		return
-	}
-	prEvents, _ := eventMap["PullRequestEvent"]
-	if len(prEvents) != 0 {
-		printPullRequestReport(prEvents, repo)
-	}
-	revEvents, _ := eventMap["PullRequestReviewEvent"]
-	revCommentEvents, _ := eventMap["PullRequestReviewCommentEvent"]
-	revEvents = append(revEvents, revCommentEvents...)
-	if len(revEvents) != 0 {
-		printPullRequestReviewReport(revEvents, repo)
-	}
-	issueEvents, _ := eventMap["IssuesEvent"]
-	if len(issueEvents) != 0 {
-		printIssueEventReport(issueEvents, repo)
-	}
-	issueCommentEvents, _ := eventMap["IssueCommentEvent"]
-	if len(issueCommentEvents) != 0 {
-		printIssueCommentEventReport(issueCommentEvents, repo)
-	}
-	fmt.Printf("============\n")
-	fmt.Printf("EVENT REPORT\n")
-	fmt.Printf("============\n")
-	for key, val := range eventMap {
-		fmt.Printf("%d %s\n", len(val), key)
+func eventFilterExcludeBots() func(*github.Event) bool {
+	return func(event *github.Event) bool {
+		return !isBot(event.GetActor())
 	}
 }
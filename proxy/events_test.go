@@ -0,0 +1,120 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+)
+
+func newTestProxy(t *testing.T, handler http.Handler) *GithubProxy {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	return &GithubProxy{
+		client:      client,
+		webURL:      "https://github.com",
+		concurrency: 1,
+		location:    time.UTC,
+		budget:      &rateBudget{},
+	}
+}
+
+func issueJSON(number int, created time.Time) string {
+	return fmt.Sprintf(`{"number":%d,"title":"issue %d","html_url":"https://github.com/o/r/issues/%d","user":{"login":"amy"},"created_at":%q,"updated_at":%q}`,
+		number, number, number, created.Format(time.RFC3339), created.Format(time.RFC3339))
+}
+
+func TestGetEventsFillingGaps(t *testing.T) {
+	defer func(max int) { EVENTS_API_MAX_EVENTS = max }(EVENTS_API_MAX_EVENTS)
+	EVENTS_API_MAX_EVENTS = 2
+	now := time.Now().UTC()
+	p := newTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/events":
+			fmt.Fprintf(w, `[{"id":"2","type":"IssuesEvent","created_at":%q,"payload":{"action":"opened","issue":%s}},
+				{"id":"1","type":"IssuesEvent","created_at":%q,"payload":{"action":"opened","issue":%s}}]`,
+				now.Add(-time.Hour).Format(time.RFC3339), issueJSON(2, now.Add(-time.Hour)),
+				now.Add(-2*time.Hour).Format(time.RFC3339), issueJSON(1, now.Add(-2*time.Hour)))
+		case "/search/issues":
+			if !strings.Contains(r.URL.Query().Get("q"), "created:") {
+				fmt.Fprint(w, `{"total_count":0,"items":[]}`)
+				return
+			}
+			fmt.Fprintf(w, `{"total_count":1,"items":[%s]}`, issueJSON(7, now.Add(-5*time.Hour)))
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+
+	report, err := p.GetEvents("o", []string{"r"}, TimeWindowSpec{Hours: 10}, EventFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo := report.Repos[0]; !repo.Truncated || len(repo.NewIssues) != 2 {
+		t.Errorf("events only: truncated %v with %d new issues, want truncated with 2", repo.Truncated, len(repo.NewIssues))
+	}
+
+	report, err = p.GetEventsFillingGaps("o", []string{"r"}, TimeWindowSpec{Hours: 10}, EventFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := report.Repos[0]
+	if repo.Truncated {
+		t.Errorf("filled report still truncated since %v", repo.CoveredSince)
+	}
+	var numbers []int
+	for _, item := range repo.NewIssues {
+		numbers = append(numbers, item.Number)
+	}
+	if fmt.Sprint(numbers) != "[1 2 7]" {
+		t.Errorf("new issues %v, want [1 2 7]", numbers)
+	}
+}
+
+func TestGetEventsIncludesWindowStart(t *testing.T) {
+	day := time.Now().UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour)
+	p := newTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var events []string
+		for i, created := range []time.Time{day.AddDate(0, 0, 1), day.Add(12 * time.Hour), day} {
+			events = append(events, fmt.Sprintf(`{"id":"%d","type":"IssuesEvent","created_at":%q,"payload":{"action":"opened","issue":%s}}`,
+				3-i, created.Format(time.RFC3339), issueJSON(3-i, created)))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(events, ","))
+	}))
+
+	report, err := p.GetEvents("o", []string{"r"}, TimeWindowSpec{Date: day.Format(DAY_LAYOUT)}, EventFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var numbers []int
+	for _, item := range report.Repos[0].NewIssues {
+		numbers = append(numbers, item.Number)
+	}
+	if fmt.Sprint(numbers) != "[1 2]" {
+		t.Errorf("new issues %v, want [1 2] from the start of the day up to its end", numbers)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+)
+
+var ISSUES_PER_PAGE int = 100
+
+type IssueReport struct {
+	Org    string
+	Repos  []string
+	Issues []IssueSummary
+}
+
+type IssueSummary struct {
+	Repo                        string
+	Number                      int
+	Title                       string
+	URL                         string
+	Author                      string
+	Labels                      []string
+	Assignees                   []string
+	Milestone                   string
+	CreatedAt                   time.Time
+	UpdatedAt                   time.Time
+	Comments                    int
+	LastMaintainerResponseAt    *time.Time
+	LastMaintainerResponseBy    string
+	DaysSinceMaintainerResponse int
+}
+
+func (p *GithubProxy) GetIssues(org string, repos []string, maintainerOpts MaintainerOptions) (*IssueReport, error) {
+	ctx := context.Background()
+	maintainers, err := p.loadMaintainers(ctx, org, maintainerOpts)
+	if err != nil {
+		return nil, err
+	}
+	repoIssues := make([][]IssueSummary, len(repos))
+	err = p.forEachRepo(ctx, repos, func(ctx context.Context, i int, repo string) error {
+		summaries, err := p.getIssueSummaries(ctx, org, repo, maintainers)
+		repoIssues[i] = summaries
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	report := &IssueReport{
+		Org:   org,
+		Repos: repos,
+	}
+	for _, summaries := range repoIssues {
+		report.Issues = append(report.Issues, summaries...)
+	}
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.DaysSinceMaintainerResponse != b.DaysSinceMaintainerResponse {
+			return a.DaysSinceMaintainerResponse > b.DaysSinceMaintainerResponse
+		}
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		return a.Number < b.Number
+	})
+	return report, nil
+}
+
+func (p *GithubProxy) getIssueSummaries(
+	ctx context.Context,
+	org, repo string,
+	maintainers *maintainerSet) ([]IssueSummary, error) {
+	issues, err := p.getAllOpenIssues(ctx, org, repo)
+	if err != nil {
+		return nil, err
+	}
+	var summaries []IssueSummary
+	for _, issue := range issues {
+		summary := IssueSummary{
+			Repo:      repo,
+			Number:    issue.GetNumber(),
+			Title:     issue.GetTitle(),
+			URL:       issue.GetHTMLURL(),
+			Author:    issue.GetUser().GetLogin(),
+			Milestone: issue.GetMilestone().GetTitle(),
+			CreatedAt: issue.GetCreatedAt(),
+			UpdatedAt: issue.GetUpdatedAt(),
+			Comments:  issue.GetComments(),
+		}
+		for _, label := range issue.Labels {
+			summary.Labels = append(summary.Labels, label.GetName())
+		}
+		for _, assignee := range issue.Assignees {
+			summary.Assignees = append(summary.Assignees, assignee.GetLogin())
+		}
+		lastResponseAt := summary.CreatedAt
+		if summary.Comments != 0 {
+			comments, err := p.getIssueComments(ctx, org, repo, summary.Number)
+			if err != nil {
+				return nil, err
+			}
+			responses, err := maintainers.commentResponses(ctx, repo, comments)
+			if err != nil {
+				return nil, err
+			}
+			if last := lastResponse(summary.Author, responses); last != nil {
+				summary.LastMaintainerResponseAt = &last.At
+				summary.LastMaintainerResponseBy = last.By
+				lastResponseAt = last.At
+			}
+		}
+		summary.DaysSinceMaintainerResponse = getDaysSince(lastResponseAt)
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+func (p *GithubProxy) getAllOpenIssues(ctx context.Context, org, repo string) ([]*github.Issue, error) {
+	var openIssues []*github.Issue
+	opts := &github.IssueListByRepoOptions{
+		State:     "open",
+		Sort:      "created",
+		Direction: "asc",
+		ListOptions: github.ListOptions{
+			PerPage: ISSUES_PER_PAGE,
+			Page:    1,
+		},
+	}
+	for {
+		issues, resp, err := p.client.Issues.ListByRepo(ctx, org, repo, opts)
+		if err != nil {
+			return nil, wrapAPIError(err, fmt.Sprintf("listing issues for %s/%s", org, repo))
+		}
+		for _, issue := range issues {
+			if !issue.IsPullRequest() {
+				openIssues = append(openIssues, issue)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return openIssues, nil
+}
+
+func (p *GithubProxy) getIssueComments(ctx context.Context, org, repo string, number int) ([]*github.IssueComment, error) {
+	return p.getIssueCommentsSince(ctx, org, repo, number, time.Time{})
+}
+
+// getIssueCommentsSince lists the comments updated at or after since, all of
+// them when since is zero.
+func (p *GithubProxy) getIssueCommentsSince(ctx context.Context, org, repo string, number int, since time.Time) ([]*github.IssueComment, error) {
+	var comments []*github.IssueComment
+	opts := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{
+			PerPage: ISSUES_PER_PAGE,
+			Page:    1,
+		},
+	}
+	if !since.IsZero() {
+		opts.Since = &since
+	}
+	for {
+		page, resp, err := p.client.Issues.ListComments(ctx, org, repo, number, opts)
+		if err != nil {
+			return nil, wrapAPIError(err, fmt.Sprintf("listing comments for %s/%s#%d", org, repo, number))
+		}
+		comments = append(comments, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return comments, nil
+}
@@ -0,0 +1,209 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+)
+
+// Author associations that may come with write access to a repository.
+// Both include read and triage access, so users with them are looked up
+// with the collaborator permission API.
+var maintainerAssociations = map[string]bool{
+	"MEMBER":       true,
+	"COLLABORATOR": true,
+}
+
+// Collaborator permissions that grant write access to a repository.
+var maintainerPermissions = map[string]bool{
+	"admin": true,
+	"write": true,
+}
+
+// MaintainerOptions adds the members of teams, given as org/team or as a
+// team slug of the org being reported on, to the users with write access.
+type MaintainerOptions struct {
+	Teams []string
+}
+
+type maintainerSet struct {
+	proxy   *GithubProxy
+	org     string
+	members map[string]bool
+	// Teams name the maintainers explicitly, org members outside them are
+	// not taken as maintainers when permissions can't be looked up.
+	teams bool
+
+	mu          sync.Mutex
+	permissions map[string]bool
+	unreadable  map[string]bool
+}
+
+type response struct {
+	By string
+	At time.Time
+}
+
+func (p *GithubProxy) loadMaintainers(ctx context.Context, org string, opts MaintainerOptions) (*maintainerSet, error) {
+	set := &maintainerSet{
+		proxy:       p,
+		org:         org,
+		members:     make(map[string]bool),
+		teams:       len(opts.Teams) != 0,
+		permissions: make(map[string]bool),
+		unreadable:  make(map[string]bool),
+	}
+	for _, team := range opts.Teams {
+		teamOrg, slug := org, team
+		if i := strings.Index(team, "/"); i >= 0 {
+			teamOrg, slug = team[:i], team[i+1:]
+		}
+		listOpts := &github.TeamListTeamMembersOptions{
+			ListOptions: github.ListOptions{PerPage: 100, Page: 1},
+		}
+		for {
+			members, resp, err := p.client.Teams.ListTeamMembersBySlug(ctx, teamOrg, slug, listOpts)
+			if err != nil {
+				return nil, wrapAPIError(err, fmt.Sprintf("listing members of team %s/%s", teamOrg, slug))
+			}
+			for _, member := range members {
+				set.members[strings.ToLower(member.GetLogin())] = true
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			listOpts.Page = resp.NextPage
+		}
+	}
+	return set, nil
+}
+
+func (m *maintainerSet) isMaintainer(ctx context.Context, repo string, user *github.User, association string) (bool, error) {
+	switch {
+	case isBot(user):
+		return false, nil
+	case association == "OWNER" || m.members[strings.ToLower(user.GetLogin())]:
+		return true, nil
+	case !maintainerAssociations[association]:
+		return false, nil
+	}
+	return m.hasWriteAccess(ctx, repo, user.GetLogin(), association)
+}
+
+// hasWriteAccess looks up the permission of a user once per repository.
+// Reading permissions needs push access, without it the author association
+// is used instead.
+func (m *maintainerSet) hasWriteAccess(ctx context.Context, repo, login, association string) (bool, error) {
+	key := repo + "/" + strings.ToLower(login)
+	m.mu.Lock()
+	allowed, found := m.permissions[key]
+	unreadable := m.unreadable[repo]
+	m.mu.Unlock()
+	if found {
+		return allowed, nil
+	}
+	if unreadable {
+		return m.associationFallback(association), nil
+	}
+	level, _, err := m.proxy.client.Repositories.GetPermissionLevel(ctx, m.org, repo, login)
+	if err != nil {
+		var respErr *github.ErrorResponse
+		if !errors.As(err, &respErr) || respErr.Response == nil || respErr.Response.StatusCode != http.StatusForbidden {
+			return false, wrapAPIError(err, fmt.Sprintf("getting permission of %s for %s/%s", login, m.org, repo))
+		}
+		m.mu.Lock()
+		m.unreadable[repo] = true
+		m.mu.Unlock()
+		return m.associationFallback(association), nil
+	}
+	allowed = maintainerPermissions[level.GetPermission()]
+	m.mu.Lock()
+	m.permissions[key] = allowed
+	m.mu.Unlock()
+	return allowed, nil
+}
+
+func (m *maintainerSet) associationFallback(association string) bool {
+	return association == "COLLABORATOR" || association == "MEMBER" && !m.teams
+}
+
+func (m *maintainerSet) commentResponses(ctx context.Context, repo string, comments []*github.IssueComment) ([]response, error) {
+	var responses []response
+	for _, comment := range comments {
+		maintainer, err := m.isMaintainer(ctx, repo, comment.GetUser(), comment.GetAuthorAssociation())
+		if err != nil {
+			return nil, err
+		}
+		if maintainer {
+			responses = append(responses, response{By: comment.GetUser().GetLogin(), At: comment.GetCreatedAt()})
+		}
+	}
+	return responses, nil
+}
+
+func (m *maintainerSet) reviewResponses(ctx context.Context, repo string, reviews []*github.PullRequestReview) ([]response, error) {
+	var responses []response
+	for _, review := range reviews {
+		if review.SubmittedAt == nil {
+			continue
+		}
+		maintainer, err := m.isMaintainer(ctx, repo, review.GetUser(), review.GetAuthorAssociation())
+		if err != nil {
+			return nil, err
+		}
+		if maintainer {
+			responses = append(responses, response{By: review.GetUser().GetLogin(), At: review.GetSubmittedAt()})
+		}
+	}
+	return responses, nil
+}
+
+// firstResponse and lastResponse ignore the author of the item, a maintainer
+// following up on their own issue is not a response to it.
+func firstResponse(author string, responses []response) *response {
+	var first *response
+	for i := range responses {
+		if strings.EqualFold(responses[i].By, author) {
+			continue
+		}
+		if first == nil || responses[i].At.Before(first.At) {
+			first = &responses[i]
+		}
+	}
+	return first
+}
+
+func lastResponse(author string, responses []response) *response {
+	var last *response
+	for i := range responses {
+		if strings.EqualFold(responses[i].By, author) {
+			continue
+		}
+		if last == nil || responses[i].At.After(last.At) {
+			last = &responses[i]
+		}
+	}
+	return last
+}
@@ -0,0 +1,80 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+)
+
+func TestIsMaintainerChecksPermission(t *testing.T) {
+	lookups := map[string]int{}
+	p := newTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups[r.URL.Path]++
+		switch r.URL.Path {
+		case "/repos/o/r/collaborators/writer/permission":
+			w.Write([]byte(`{"permission":"write"}`))
+		case "/repos/o/r/collaborators/triager/permission":
+			w.Write([]byte(`{"permission":"read"}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"Must have push access to view collaborator permission."}`))
+		}
+	}))
+	m, err := p.loadMaintainers(context.Background(), "o", MaintainerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		repo        string
+		login       string
+		association string
+		want        bool
+	}{
+		{"r", "owner", "OWNER", true},
+		{"r", "writer", "MEMBER", true},
+		{"r", "writer", "MEMBER", true},
+		{"r", "triager", "COLLABORATOR", false},
+		{"r", "outsider", "CONTRIBUTOR", false},
+		{"r", "dependabot[bot]", "COLLABORATOR", false},
+		{"other", "member", "MEMBER", true},
+		{"other", "collaborator", "COLLABORATOR", true},
+	}
+	for _, test := range tests {
+		got, err := m.isMaintainer(context.Background(), test.repo, &github.User{Login: github.String(test.login)}, test.association)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("isMaintainer(%s, %s, %s) = %v, want %v", test.repo, test.login, test.association, got, test.want)
+		}
+	}
+	if n := lookups["/repos/o/r/collaborators/writer/permission"]; n != 1 {
+		t.Errorf("looked up the permission of writer %d times, want 1", n)
+	}
+	if n := lookups["/repos/o/other/collaborators/collaborator/permission"]; n != 0 {
+		t.Errorf("looked up permissions %d times after push access was refused, want 0", n)
+	}
+
+	m.teams = true
+	if got, _ := m.isMaintainer(context.Background(), "other", &github.User{Login: github.String("member2")}, "MEMBER"); got {
+		t.Error("org member outside the maintainer teams is a maintainer")
+	}
+}
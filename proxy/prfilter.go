@@ -0,0 +1,115 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+)
+
+const (
+	DRAFT_ANY   string = ""
+	DRAFT_ONLY  string = "draft"
+	DRAFT_READY string = "ready"
+)
+
+type PullRequestFilter struct {
+	Authors        []string
+	ExcludeAuthors []string
+	ExcludeBots    bool
+	Labels         []string
+	Draft          string
+	Base           string
+	// MinDays and MaxDays bound DaysSinceLastAction, a negative value leaves
+	// the bound unset.
+	MinDays       int
+	MaxDays       int
+	CreatedBefore time.Time
+	CreatedAfter  time.Time
+}
+
+func NewPullRequestFilter() PullRequestFilter {
+	return PullRequestFilter{
+		MinDays: -1,
+		MaxDays: -1,
+	}
+}
+
+func (f PullRequestFilter) Validate() error {
+	switch f.Draft {
+	case DRAFT_ANY, DRAFT_ONLY, DRAFT_READY:
+	default:
+		return fmt.Errorf("Unknown draft filter '%s' must be one of [%s,%s]", f.Draft, DRAFT_ONLY, DRAFT_READY)
+	}
+	if f.MinDays >= 0 && f.MaxDays >= 0 && f.MinDays > f.MaxDays {
+		return fmt.Errorf("Minimum days %d is greater than maximum days %d", f.MinDays, f.MaxDays)
+	}
+	return nil
+}
+
+// matchesPullRequest applies the filters that only need the listed pull
+// request, before any further API calls are made for it.
+func (f PullRequestFilter) matchesPullRequest(pr *github.PullRequest) bool {
+	author := pr.GetUser().GetLogin()
+	if len(f.Authors) != 0 && !containsAny([]string{author}, f.Authors) {
+		return false
+	}
+	if containsAny([]string{author}, f.ExcludeAuthors) {
+		return false
+	}
+	if f.ExcludeBots && isBot(pr.GetUser()) {
+		return false
+	}
+	if len(f.Labels) != 0 {
+		var labels []string
+		for _, label := range pr.Labels {
+			labels = append(labels, label.GetName())
+		}
+		if !containsAny(labels, f.Labels) {
+			return false
+		}
+	}
+	if f.Draft == DRAFT_ONLY && !pr.GetDraft() {
+		return false
+	}
+	if f.Draft == DRAFT_READY && pr.GetDraft() {
+		return false
+	}
+	if f.Base != "" && !strings.EqualFold(pr.GetBase().GetRef(), f.Base) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !pr.GetCreatedAt().Before(f.CreatedBefore) {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && !pr.GetCreatedAt().After(f.CreatedAfter) {
+		return false
+	}
+	return true
+}
+
+func (f PullRequestFilter) matchesSummary(summary *PullRequestSummary) bool {
+	if f.MinDays >= 0 && summary.DaysSinceLastAction < f.MinDays {
+		return false
+	}
+	if f.MaxDays >= 0 && summary.DaysSinceLastAction > f.MaxDays {
+		return false
+	}
+	return true
+}
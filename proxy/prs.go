@@ -19,7 +19,7 @@ package proxy
 import (
 	"context"
 	"fmt"
-	"strings"
+	"sort"
 	"time"
 
 	"github.com/google/go-github/v48/github"
@@ -27,44 +27,148 @@ import (
 
 var (
 	PRS_PER_PAGE int = 100
-	DATE_FORMAT string = "2006-Jan-02"
 )
 
-func (p *GithubProxy) GetPullRequests(org, repo string) {
+type PullRequestReport struct {
+	Org          string
+	Repos        []string
+	PullRequests []PullRequestSummary
+}
+
+type PullRequestSummary struct {
+	Repo                string
+	Number              int
+	Title               string
+	URL                 string
+	Draft               bool
+	DaysSinceLastAction int
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Author              string
+	Base                string
+	Labels              []string
+	Comments            int
+	LastCommentAt       *time.Time
+	LastCommentAuthor   string
+	LastActionAt        time.Time
+	LastActionKind      string
+	LastActionBy        string
+	Reviews             []Review
+	RequestedReviewers  []string
+	ReviewDecision      string
+	WaitingOn           string
+	CIStatus            string
+	CommitStatus        string
+	CheckStatus         string
+	MergeableState      string
+	BehindBy            int
+}
+
+func (p *GithubProxy) GetPullRequests(
+	org string,
+	repos []string,
+	filter PullRequestFilter,
+	details PullRequestDetails) (*PullRequestReport, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
 	ctx := context.Background()
+	repoPRs := make([][]PullRequestSummary, len(repos))
+	err := p.forEachRepo(ctx, repos, func(ctx context.Context, i int, repo string) error {
+		summaries, err := p.getPullRequestSummaries(ctx, org, repo, filter, details)
+		repoPRs[i] = summaries
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	report := &PullRequestReport{
+		Org:   org,
+		Repos: repos,
+	}
+	for _, summaries := range repoPRs {
+		report.PullRequests = append(report.PullRequests, summaries...)
+	}
+	sort.SliceStable(report.PullRequests, func(i, j int) bool {
+		a, b := report.PullRequests[i], report.PullRequests[j]
+		if a.DaysSinceLastAction != b.DaysSinceLastAction {
+			return a.DaysSinceLastAction > b.DaysSinceLastAction
+		}
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		return a.Number < b.Number
+	})
+	return report, nil
+}
+
+func (p *GithubProxy) getPullRequestSummaries(
+	ctx context.Context,
+	org, repo string,
+	filter PullRequestFilter,
+	details PullRequestDetails) ([]PullRequestSummary, error) {
 	pullRequests, err := p.getAllOpenPullRequests(ctx, org, repo)
 	if err != nil {
-		fmt.Printf("Failed to get pull requests: %v\n", err)
-		return
+		return nil, err
 	}
-	var output = []string{}
-	titleLine := "Title,URL,DaysSinceLastAction,Created,Updated,PR Author,LastCommentDate,CommentAuthor"
-	output = append(output, titleLine)
+	var summaries []PullRequestSummary
 	for _, PR := range pullRequests {
-		comment := p.getLastComment(ctx, org, repo, *PR.Number)
-		commentCsv := ""
-		if comment != nil {
-			commentCsv = fmt.Sprintf("%v,%s",
-				comment.CreatedAt.Format(DATE_FORMAT),
-				*comment.User.Login)
+		if !filter.matchesPullRequest(PR) {
+			continue
 		}
-		daysSince := getDaysSinceLastAction(PR, comment) 
-		
-		csvLine := fmt.Sprintf("%s,%s,%t,%d,%v,%v,%s,%d,%s",
-			sanitizeTitle(*PR.Title),
-			*PR.HTMLURL,
-			*PR.Draft,
-			daysSince,
-			PR.CreatedAt.Format(DATE_FORMAT),
-			PR.UpdatedAt.Format(DATE_FORMAT),
-			*PR.User.Login,
-			PR.Comments,
-			commentCsv)
-		output = append(output, csvLine)
-	}
-	for _, line := range output {
-		fmt.Println(line)
+		timeline, err := p.getTimeline(ctx, org, repo, *PR.Number)
+		if err != nil {
+			return nil, err
+		}
+		activity := summarizeTimeline(PR.GetUser().GetLogin(), PR.GetCreatedAt(), timeline)
+		reviews, err := p.getReviews(ctx, org, repo, *PR.Number)
+		if err != nil {
+			return nil, err
+		}
+		summary := PullRequestSummary{
+			Repo:                repo,
+			Number:              PR.GetNumber(),
+			Title:               PR.GetTitle(),
+			URL:                 PR.GetHTMLURL(),
+			Draft:               PR.GetDraft(),
+			DaysSinceLastAction: getDaysSince(activity.LastAction.At),
+			CreatedAt:           PR.GetCreatedAt(),
+			UpdatedAt:           PR.GetUpdatedAt(),
+			Author:              PR.GetUser().GetLogin(),
+			Base:                PR.GetBase().GetRef(),
+			Reviews:             latestReviews(PR.GetUser().GetLogin(), reviews),
+			RequestedReviewers:  requestedReviewers(org, PR),
+			Comments:            activity.Comments,
+			LastActionAt:        activity.LastAction.At,
+			LastActionKind:      activity.LastAction.Kind,
+			LastActionBy:        activity.LastAction.Actor,
+		}
+		summary.ReviewDecision = reviewDecision(summary.Reviews)
+		summary.WaitingOn = waitingOn(&summary)
+		for _, label := range PR.Labels {
+			summary.Labels = append(summary.Labels, label.GetName())
+		}
+		if activity.LastComment != nil {
+			summary.LastCommentAt = &activity.LastComment.At
+			summary.LastCommentAuthor = activity.LastComment.Actor
+		}
+		if !filter.matchesSummary(&summary) {
+			continue
+		}
+		if details.Status {
+			status, err := p.getPullRequestStatus(ctx, org, repo, PR)
+			if err != nil {
+				return nil, err
+			}
+			summary.CommitStatus = status.CommitStatus
+			summary.CheckStatus = status.CheckStatus
+			summary.CIStatus = combineCIStatus(status.CommitStatus, status.CheckStatus)
+			summary.MergeableState = status.MergeableState
+			summary.BehindBy = status.BehindBy
+		}
+		summaries = append(summaries, summary)
 	}
+	return summaries, nil
 }
 
 func (p *GithubProxy) getAllOpenPullRequests(ctx context.Context, org, repo string) ([]*github.PullRequest, error) {
@@ -83,8 +187,7 @@ func (p *GithubProxy) getAllOpenPullRequests(ctx context.Context, org, repo stri
 		}
 		pullRequests, _, err := p.client.PullRequests.List(ctx, org, repo, prOpts)
 		if err != nil {
-			fmt.Printf("Error Listing Pull Requests: %v\n", err)
-
+			return nil, wrapAPIError(err, fmt.Sprintf("listing pull requests for %s/%s", org, repo))
 		}
 		if len(pullRequests) == 0 {
 			morePRs = false
@@ -96,37 +199,7 @@ func (p *GithubProxy) getAllOpenPullRequests(ctx context.Context, org, repo stri
 	return openPRs, nil
 }
 
-func (p *GithubProxy) getLastComment(ctx context.Context, org, repo string, prNum int) *github.PullRequestComment {
-	commentOpts := &github.PullRequestListCommentsOptions{
-		Sort:      "Created",
-		Direction: "desc",
-		ListOptions: github.ListOptions{
-			Page:    1,
-			PerPage: 1,
-		},
-	}
-	comments, _, _ := p.client.PullRequests.ListComments(ctx, org, repo, prNum, commentOpts)
-	if len(comments) == 0 {
-		return nil
-	}
-	return comments[0]
-}
-
-func getDaysSinceLastAction(pr *github.PullRequest, comment *github.PullRequestComment ) int {
-	maxTime := pr.CreatedAt 
-	if pr.UpdatedAt.After(*maxTime) {
-		maxTime = pr.UpdatedAt 
-	}
-	if comment != nil {
-		if comment.CreatedAt.After(*maxTime) {
-			maxTime = comment.CreatedAt
-		}
-	}
-	sinceMax := time.Since(*maxTime)	
-	return int(sinceMax.Hours() / 24)
-}
-
-func sanitizeTitle(title string) string {
-	title = strings.ReplaceAll(title, "\"", "\"\"")
-	return fmt.Sprintf("\"%s\"", title)
+func getDaysSince(lastAction time.Time) int {
+	sinceLast := time.Since(lastAction)
+	return int(sinceLast.Hours() / 24)
 }
@@ -0,0 +1,223 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+)
+
+type EventReport struct {
+	Org   string    `json:"org"`
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	// Date labels calendar windows, a day, ISO week or month.
+	Date  string            `json:"date,omitempty"`
+	Repos []RepoEventReport `json:"repos"`
+}
+
+type RepoEventReport struct {
+	Repo                 string       `json:"repo"`
+	URL                  string       `json:"url"`
+	MergedPRs            []Item       `json:"merged_prs"`
+	NewPRs               []Item       `json:"new_prs"`
+	ReviewActivity       []Activity   `json:"review_activity"`
+	NewIssues            []Item       `json:"new_issues"`
+	ClosedIssues         []Item       `json:"closed_issues"`
+	IssueCommentActivity []Activity   `json:"issue_comment_activity"`
+	EventCounts          []EventCount `json:"event_counts"`
+	// Truncated is set when the Events API retention limit or a gap in the
+	// event store cut the window short, CoveredSince is then the oldest time
+	// the events cover. CoveredUntil is set when the window reaches past the
+	// last sync of the event store.
+	Truncated    bool       `json:"truncated,omitempty"`
+	CoveredSince *time.Time `json:"covered_since,omitempty"`
+	CoveredUntil *time.Time `json:"covered_until,omitempty"`
+}
+
+type Item struct {
+	Number int    `json:"number"`
+	Author string `json:"author"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+}
+
+type Activity struct {
+	Count int    `json:"count"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+type EventCount struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+func (r RepoEventReport) Empty() bool {
+	return len(r.EventCounts) == 0
+}
+
+// Coverage describes the part of a truncated window the events cover, e.g.
+// "since 2006-01-02T15:04:05Z".
+func (r RepoEventReport) Coverage() string {
+	switch {
+	case r.CoveredSince != nil && r.CoveredUntil != nil:
+		return fmt.Sprintf("from %s until %s", r.CoveredSince.Format(time.RFC3339), r.CoveredUntil.Format(time.RFC3339))
+	case r.CoveredUntil != nil:
+		return "until " + r.CoveredUntil.Format(time.RFC3339)
+	case r.CoveredSince != nil:
+		return "since " + r.CoveredSince.Format(time.RFC3339)
+	}
+	return ""
+}
+
+func buildRepoEventReport(repo, url string, events []*github.Event) RepoEventReport {
+	report := RepoEventReport{
+		Repo: repo,
+		URL:  url,
+	}
+	eventMap := make(map[string][]*github.Event)
+	for _, event := range events {
+		eventMap[*event.Type] = append(eventMap[*event.Type], event)
+	}
+	report.MergedPRs, report.NewPRs = summarizePullRequestEvents(eventMap["PullRequestEvent"])
+	revEvents := append(eventMap["PullRequestReviewEvent"], eventMap["PullRequestReviewCommentEvent"]...)
+	report.ReviewActivity = summarizeReviewEvents(revEvents)
+	report.NewIssues, report.ClosedIssues = summarizeIssueEvents(eventMap["IssuesEvent"])
+	report.IssueCommentActivity = summarizeIssueCommentEvents(eventMap["IssueCommentEvent"])
+	for key, val := range eventMap {
+		report.EventCounts = append(report.EventCounts, EventCount{Type: key, Count: len(val)})
+	}
+	sort.Slice(report.EventCounts, func(i, j int) bool {
+		return report.EventCounts[i].Type < report.EventCounts[j].Type
+	})
+	return report
+}
+
+func summarizePullRequestEvents(events []*github.Event) (merged []Item, opened []Item) {
+	for _, event := range events {
+		payload, err := event.ParsePayload()
+		if err != nil {
+			continue
+		}
+		prEvent := payload.(*github.PullRequestEvent)
+		item := Item{
+			Number: prEvent.GetNumber(),
+			Author: prEvent.GetPullRequest().GetUser().GetLogin(),
+			Title:  prEvent.GetPullRequest().GetTitle(),
+			URL:    prEvent.GetPullRequest().GetHTMLURL(),
+		}
+		if prEvent.GetAction() == "opened" {
+			opened = append(opened, item)
+		}
+		if prEvent.GetAction() == "closed" && prEvent.GetPullRequest().GetMerged() {
+			merged = append(merged, item)
+		}
+	}
+	sortItems(merged)
+	sortItems(opened)
+	return merged, opened
+}
+
+func getPullRequestURLFromReview(event *github.Event) (string, string) {
+	payload, err := event.ParsePayload()
+	if err != nil {
+		return "", ""
+	}
+	switch rev := payload.(type) {
+	case *github.PullRequestReviewEvent:
+		return rev.GetPullRequest().GetHTMLURL(), rev.GetPullRequest().GetTitle()
+	case *github.PullRequestReviewCommentEvent:
+		return rev.GetPullRequest().GetHTMLURL(), rev.GetPullRequest().GetTitle()
+	}
+	return "", ""
+}
+
+func summarizeReviewEvents(events []*github.Event) []Activity {
+	reviewMap := make(map[string]int)
+	titleMap := make(map[string]string)
+	for _, event := range events {
+		url, title := getPullRequestURLFromReview(event)
+		reviewMap[url] = reviewMap[url] + 1
+		titleMap[url] = title
+	}
+	return activityFromMaps(reviewMap, titleMap)
+}
+
+func summarizeIssueEvents(events []*github.Event) (opened []Item, closed []Item) {
+	for _, event := range events {
+		payload, err := event.ParsePayload()
+		if err != nil {
+			continue
+		}
+		issuesEvent := payload.(*github.IssuesEvent)
+		item := Item{
+			Number: issuesEvent.GetIssue().GetNumber(),
+			Author: issuesEvent.GetIssue().GetUser().GetLogin(),
+			Title:  issuesEvent.GetIssue().GetTitle(),
+			URL:    issuesEvent.GetIssue().GetHTMLURL(),
+		}
+		if issuesEvent.GetAction() == "opened" {
+			opened = append(opened, item)
+		}
+		if issuesEvent.GetAction() == "closed" {
+			closed = append(closed, item)
+		}
+	}
+	sortItems(opened)
+	sortItems(closed)
+	return opened, closed
+}
+
+func summarizeIssueCommentEvents(events []*github.Event) []Activity {
+	commentMap := make(map[string]int)
+	titleMap := make(map[string]string)
+	for _, event := range events {
+		payload, err := event.ParsePayload()
+		if err != nil {
+			continue
+		}
+		commentEvent := payload.(*github.IssueCommentEvent)
+		url := commentEvent.GetIssue().GetHTMLURL()
+		commentMap[url] = commentMap[url] + 1
+		titleMap[url] = commentEvent.GetIssue().GetTitle()
+	}
+	return activityFromMaps(commentMap, titleMap)
+}
+
+func activityFromMaps(countMap map[string]int, titleMap map[string]string) []Activity {
+	var activity []Activity
+	for url, val := range countMap {
+		activity = append(activity, Activity{Count: val, Title: titleMap[url], URL: url})
+	}
+	sort.Slice(activity, func(i, j int) bool {
+		if activity[i].Count != activity[j].Count {
+			return activity[i].Count > activity[j].Count
+		}
+		return activity[i].URL < activity[j].URL
+	})
+	return activity
+}
+
+func sortItems(items []Item) {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Number < items[j].Number
+	})
+}
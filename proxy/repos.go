@@ -19,21 +19,172 @@ package proxy
 import (
 	"context"
 	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v48/github"
 )
 
-func (p *GithubProxy) GetReposForOrg(org string) []string {
+var REPOS_PER_PAGE int = 100
+
+type RepoFilter struct {
+	ExcludeArchived bool
+	ExcludeForks    bool
+	Include         []string
+	Exclude         []string
+	Topics          []string
+	Visibility      string
+	Languages       []string
+}
+
+type nameMatcher func(string) bool
+
+func (f RepoFilter) Validate() error {
+	switch f.Visibility {
+	case "", "all", "public", "private", "internal":
+	default:
+		return fmt.Errorf("Unknown visibility '%s' must be one of [all,public,private,internal]", f.Visibility)
+	}
+	if _, err := newNameMatchers(f.Include); err != nil {
+		return err
+	}
+	_, err := newNameMatchers(f.Exclude)
+	return err
+}
+
+func (p *GithubProxy) GetReposForOrg(org string, filter RepoFilter) ([]string, error) {
 	ctx := context.Background()
-	repos, _, err := p.client.Repositories.ListByOrg(ctx, org, nil)
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	include, err := newNameMatchers(filter.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := newNameMatchers(filter.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	repoType := "all"
+	if filter.Visibility != "" {
+		repoType = filter.Visibility
+	}
+	repos, err := p.listOrgRepos(ctx, org, repoType)
 	if err != nil {
-		fmt.Printf("%v\n", err)
-		return nil
+		return nil, err
 	}
 	var repoStrings = []string{}
 	for _, repo := range repos {
-		repoName := *repo.Name
-		if repoName[0:1] != "." {
-			repoStrings = append(repoStrings, repoName)
+		repoName := repo.GetName()
+		if repoName[0:1] == "." {
+			continue
+		}
+		if filter.ExcludeArchived && repo.GetArchived() {
+			continue
+		}
+		if filter.ExcludeForks && repo.GetFork() {
+			continue
+		}
+		if repoType != "all" && repo.GetVisibility() != "" && repo.GetVisibility() != repoType {
+			continue
+		}
+		if len(include) != 0 && !matchAny(include, repoName) {
+			continue
+		}
+		if matchAny(exclude, repoName) {
+			continue
+		}
+		if len(filter.Topics) != 0 && !containsAny(repo.Topics, filter.Topics) {
+			continue
+		}
+		if len(filter.Languages) != 0 && !containsAny([]string{repo.GetLanguage()}, filter.Languages) {
+			continue
+		}
+		repoStrings = append(repoStrings, repoName)
+	}
+	sort.Strings(repoStrings)
+	return repoStrings, nil
+}
+
+func (p *GithubProxy) listOrgRepos(ctx context.Context, org, repoType string) ([]*github.Repository, error) {
+	var allRepos []*github.Repository
+	opts := &github.RepositoryListByOrgOptions{
+		Type: repoType,
+		ListOptions: github.ListOptions{
+			PerPage: REPOS_PER_PAGE,
+			Page:    1,
+		},
+	}
+	for {
+		repos, resp, err := p.client.Repositories.ListByOrg(ctx, org, opts)
+		if err != nil {
+			return nil, wrapAPIError(err, fmt.Sprintf("listing repositories for %s", org))
+		}
+		allRepos = append(allRepos, repos...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return allRepos, nil
+}
+
+// newNameMatchers accepts shell globs, or regular expressions when the
+// pattern is prefixed with "re:". Globs ignore case. A pattern without * or ?
+// matches the name exactly, so bot logins such as dependabot[bot] are not
+// read as character classes. A trailing [bot] in a glob is literal for the
+// same reason.
+func newNameMatchers(patterns []string) ([]nameMatcher, error) {
+	var matchers []nameMatcher
+	for _, pattern := range patterns {
+		if !strings.HasPrefix(pattern, "re:") && !strings.ContainsAny(pattern, "*?") {
+			exact := pattern
+			matchers = append(matchers, func(name string) bool {
+				return strings.EqualFold(exact, name)
+			})
+			continue
+		}
+		if strings.HasPrefix(pattern, "re:") {
+			re, err := regexp.Compile(strings.TrimPrefix(pattern, "re:"))
+			if err != nil {
+				return nil, fmt.Errorf("Invalid name pattern '%s': %v", pattern, err)
+			}
+			matchers = append(matchers, re.MatchString)
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("Invalid name pattern '%s': %v", pattern, err)
+		}
+		glob := strings.ToLower(pattern)
+		if strings.HasSuffix(glob, BOT_SUFFIX) {
+			glob = strings.TrimSuffix(glob, BOT_SUFFIX) + `\[bot\]`
+		}
+		matchers = append(matchers, func(name string) bool {
+			matched, _ := path.Match(glob, strings.ToLower(name))
+			return matched
+		})
+	}
+	return matchers, nil
+}
+
+func matchAny(matchers []nameMatcher, name string) bool {
+	for _, matcher := range matchers {
+		if matcher(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values, wanted []string) bool {
+	for _, value := range values {
+		for _, want := range wanted {
+			if strings.EqualFold(value, want) {
+				return true
+			}
 		}
 	}
-	return repoStrings
+	return false
 }
@@ -0,0 +1,91 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+)
+
+func TestNameMatchers(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"dependabot[bot]", "dependabot[bot]", true},
+		{"dependabot[bot]", "Dependabot[bot]", true},
+		{"dependabot[bot]", "dependabott", false},
+		{"renovate*", "renovate[bot]", true},
+		{"Renovate*", "renovate[bot]", true},
+		{"renovate*", "Renovate-Bot", true},
+		{"*[bot]", "github-actions[bot]", true},
+		{"*[bot]", "bob", false},
+		{"containerd-*", "containerd-shim", true},
+		{"containerd", "containerd-shim", false},
+		{"re:^cri-", "cri-tools", true},
+	}
+	for _, test := range tests {
+		matchers, err := newNameMatchers([]string{test.pattern})
+		if err != nil {
+			t.Fatalf("newNameMatchers(%q): %v", test.pattern, err)
+		}
+		if got := matchAny(matchers, test.name); got != test.want {
+			t.Errorf("pattern %q name %q: got %v, want %v", test.pattern, test.name, got, test.want)
+		}
+	}
+}
+
+func TestEventFilterExcludeBotActor(t *testing.T) {
+	filters, err := EventFilter{ExcludeActors: []string{"dependabot[bot]"}}.filters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := []*github.Event{
+		{Actor: &github.User{Login: github.String("dependabot[bot]")}},
+		{Actor: &github.User{Login: github.String("dependabott")}},
+	}
+	for _, filter := range filters {
+		events = filterEvents(events, filter)
+	}
+	if len(events) != 1 || events[0].GetActor().GetLogin() != "dependabott" {
+		t.Errorf("got %d events, want only dependabott", len(events))
+	}
+}
+
+func TestRepoFilterValidate(t *testing.T) {
+	valid := []RepoFilter{
+		{},
+		{Visibility: "internal", Include: []string{"containerd-*", "re:^nerd"}, Exclude: []string{"website"}},
+	}
+	for _, filter := range valid {
+		if err := filter.Validate(); err != nil {
+			t.Errorf("Validate(%+v): %v", filter, err)
+		}
+	}
+	invalid := []RepoFilter{
+		{Visibility: "secret"},
+		{Include: []string{"re:("}},
+		{Exclude: []string{"[a-*"}},
+	}
+	for _, filter := range invalid {
+		if err := filter.Validate(); err == nil {
+			t.Errorf("Validate(%+v) accepted an invalid filter", filter)
+		}
+	}
+}
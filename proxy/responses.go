@@ -0,0 +1,212 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+const (
+	KIND_PULL_REQUEST string = "pr"
+	KIND_ISSUE        string = "issue"
+)
+
+var DEFAULT_RESPONSE_THRESHOLD time.Duration = 72 * time.Hour
+
+type ResponseOptions struct {
+	Threshold   time.Duration
+	Maintainers MaintainerOptions
+}
+
+type ResponseReport struct {
+	Org       string         `json:"org"`
+	Threshold time.Duration  `json:"threshold_ns"`
+	Generated time.Time      `json:"generated"`
+	Items     []ResponseItem `json:"items"`
+}
+
+type ResponseItem struct {
+	Repo            string     `json:"repo"`
+	Kind            string     `json:"kind"`
+	Number          int        `json:"number"`
+	Title           string     `json:"title"`
+	URL             string     `json:"url"`
+	Author          string     `json:"author"`
+	CreatedAt       time.Time  `json:"created_at"`
+	FirstResponseAt *time.Time `json:"first_response_at,omitempty"`
+	FirstResponseBy string     `json:"first_response_by,omitempty"`
+	// TimeToFirstResponse runs until now for items without a response.
+	TimeToFirstResponse time.Duration `json:"time_to_first_response_ns"`
+	Breached            bool          `json:"breached"`
+}
+
+func (i ResponseItem) Responded() bool {
+	return i.FirstResponseAt != nil
+}
+
+// Unanswered lists items nobody with write access has responded to within
+// the threshold, the longest waiting first.
+func (r *ResponseReport) Unanswered() []ResponseItem {
+	return r.filterItems(func(item ResponseItem) bool { return item.Breached && !item.Responded() })
+}
+
+// SlowResponses lists items whose first response came after the threshold.
+func (r *ResponseReport) SlowResponses() []ResponseItem {
+	return r.filterItems(func(item ResponseItem) bool { return item.Breached && item.Responded() })
+}
+
+func (r *ResponseReport) filterItems(keep func(ResponseItem) bool) []ResponseItem {
+	var items []ResponseItem
+	for _, item := range r.Items {
+		if keep(item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (p *GithubProxy) GetResponseReport(org string, repos []string, opts ResponseOptions) (*ResponseReport, error) {
+	ctx := context.Background()
+	if opts.Threshold <= 0 {
+		opts.Threshold = DEFAULT_RESPONSE_THRESHOLD
+	}
+	maintainers, err := p.loadMaintainers(ctx, org, opts.Maintainers)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	repoItems := make([][]ResponseItem, len(repos))
+	err = p.forEachRepo(ctx, repos, func(ctx context.Context, i int, repo string) error {
+		items, err := p.getResponseItems(ctx, org, repo, maintainers)
+		if err != nil {
+			return err
+		}
+		for j := range items {
+			setTimeToFirstResponse(&items[j], opts.Threshold, now)
+		}
+		repoItems[i] = items
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report := &ResponseReport{
+		Org:       org,
+		Threshold: opts.Threshold,
+		Generated: now,
+	}
+	for _, items := range repoItems {
+		report.Items = append(report.Items, items...)
+	}
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		a, b := report.Items[i], report.Items[j]
+		if a.TimeToFirstResponse != b.TimeToFirstResponse {
+			return a.TimeToFirstResponse > b.TimeToFirstResponse
+		}
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		return a.Number < b.Number
+	})
+	return report, nil
+}
+
+func (p *GithubProxy) getResponseItems(ctx context.Context, org, repo string, maintainers *maintainerSet) ([]ResponseItem, error) {
+	var items []ResponseItem
+	pullRequests, err := p.getAllOpenPullRequests(ctx, org, repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, pr := range pullRequests {
+		item := ResponseItem{
+			Repo:      repo,
+			Kind:      KIND_PULL_REQUEST,
+			Number:    pr.GetNumber(),
+			Title:     pr.GetTitle(),
+			URL:       pr.GetHTMLURL(),
+			Author:    pr.GetUser().GetLogin(),
+			CreatedAt: pr.GetCreatedAt(),
+		}
+		comments, err := p.getIssueComments(ctx, org, repo, item.Number)
+		if err != nil {
+			return nil, err
+		}
+		reviews, err := p.getReviews(ctx, org, repo, item.Number)
+		if err != nil {
+			return nil, err
+		}
+		responses, err := maintainers.commentResponses(ctx, repo, comments)
+		if err != nil {
+			return nil, err
+		}
+		reviewResponses, err := maintainers.reviewResponses(ctx, repo, reviews)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, reviewResponses...)
+		setFirstResponse(&item, firstResponse(item.Author, responses))
+		items = append(items, item)
+	}
+	issues, err := p.getAllOpenIssues(ctx, org, repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range issues {
+		item := ResponseItem{
+			Repo:      repo,
+			Kind:      KIND_ISSUE,
+			Number:    issue.GetNumber(),
+			Title:     issue.GetTitle(),
+			URL:       issue.GetHTMLURL(),
+			Author:    issue.GetUser().GetLogin(),
+			CreatedAt: issue.GetCreatedAt(),
+		}
+		if issue.GetComments() != 0 {
+			comments, err := p.getIssueComments(ctx, org, repo, item.Number)
+			if err != nil {
+				return nil, err
+			}
+			responses, err := maintainers.commentResponses(ctx, repo, comments)
+			if err != nil {
+				return nil, err
+			}
+			setFirstResponse(&item, firstResponse(item.Author, responses))
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func setFirstResponse(item *ResponseItem, first *response) {
+	if first == nil {
+		return
+	}
+	at := first.At
+	item.FirstResponseAt = &at
+	item.FirstResponseBy = first.By
+}
+
+func setTimeToFirstResponse(item *ResponseItem, threshold time.Duration, now time.Time) {
+	if item.FirstResponseAt != nil {
+		item.TimeToFirstResponse = item.FirstResponseAt.Sub(item.CreatedAt)
+	} else {
+		item.TimeToFirstResponse = now.Sub(item.CreatedAt)
+	}
+	item.Breached = item.TimeToFirstResponse > threshold
+}
@@ -0,0 +1,121 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v48/github"
+)
+
+const (
+	REVIEW_APPROVED          string = "approved"
+	REVIEW_CHANGES_REQUESTED string = "changes_requested"
+	REVIEW_REQUIRED          string = "review_required"
+
+	WAITING_ON_AUTHOR    string = "author"
+	WAITING_ON_REVIEWERS string = "reviewers"
+	WAITING_ON_NOBODY    string = "nobody"
+)
+
+type Review struct {
+	Reviewer string
+	State    string
+}
+
+func (p *GithubProxy) getReviews(ctx context.Context, org, repo string, prNum int) ([]*github.PullRequestReview, error) {
+	var reviews []*github.PullRequestReview
+	opts := &github.ListOptions{PerPage: PRS_PER_PAGE, Page: 1}
+	for {
+		page, resp, err := p.client.PullRequests.ListReviews(ctx, org, repo, prNum, opts)
+		if err != nil {
+			return nil, wrapAPIError(err, fmt.Sprintf("listing reviews for %s/%s#%d", org, repo, prNum))
+		}
+		reviews = append(reviews, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return reviews, nil
+}
+
+// latestReviews keeps the latest review of each reviewer in the order GitHub
+// returns them, oldest first. A plain comment does not replace an earlier
+// approval or change request, the same as the review decision on GitHub.
+func latestReviews(author string, reviews []*github.PullRequestReview) []Review {
+	states := make(map[string]string)
+	for _, review := range reviews {
+		reviewer := review.GetUser().GetLogin()
+		if reviewer == "" || reviewer == author {
+			continue
+		}
+		state := strings.ToLower(review.GetState())
+		if state == "pending" {
+			continue
+		}
+		if _, ok := states[reviewer]; ok && state == "commented" {
+			continue
+		}
+		states[reviewer] = state
+	}
+	var latest []Review
+	for reviewer, state := range states {
+		latest = append(latest, Review{Reviewer: reviewer, State: state})
+	}
+	sort.Slice(latest, func(i, j int) bool {
+		return latest[i].Reviewer < latest[j].Reviewer
+	})
+	return latest
+}
+
+func reviewDecision(reviews []Review) string {
+	decision := REVIEW_REQUIRED
+	for _, review := range reviews {
+		switch review.State {
+		case REVIEW_CHANGES_REQUESTED:
+			return REVIEW_CHANGES_REQUESTED
+		case REVIEW_APPROVED:
+			decision = REVIEW_APPROVED
+		}
+	}
+	return decision
+}
+
+func waitingOn(summary *PullRequestSummary) string {
+	if summary.Draft || summary.ReviewDecision == REVIEW_CHANGES_REQUESTED {
+		return WAITING_ON_AUTHOR
+	}
+	if len(summary.RequestedReviewers) != 0 || summary.ReviewDecision == REVIEW_REQUIRED {
+		return WAITING_ON_REVIEWERS
+	}
+	return WAITING_ON_NOBODY
+}
+
+func requestedReviewers(org string, pr *github.PullRequest) []string {
+	var requested []string
+	for _, user := range pr.RequestedReviewers {
+		requested = append(requested, user.GetLogin())
+	}
+	for _, team := range pr.RequestedTeams {
+		requested = append(requested, org+"/"+team.GetSlug())
+	}
+	return requested
+}
@@ -0,0 +1,297 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+)
+
+var (
+	SEARCH_PER_PAGE int = 100
+	// The search API returns at most SEARCH_MAX_RESULTS items per query.
+	SEARCH_MAX_RESULTS int = 1000
+)
+
+const (
+	EVENT_SOURCE_EVENTS string = "events"
+	EVENT_SOURCE_SEARCH string = "search"
+)
+
+var EVENT_SOURCES = []string{EVENT_SOURCE_EVENTS, EVENT_SOURCE_SEARCH}
+
+const searchTimeLayout = "2006-01-02T15:04:05Z"
+
+// getSearchEvents rebuilds the events of a window from the search API, for
+// windows older than the Events API keeps. Opened, merged and closed items
+// come from created:, merged: and closed: queries, and comment and review
+// activity from listing the comments of every item updated since the window
+// started.
+func (p *GithubProxy) getSearchEvents(
+	ctx context.Context,
+	org, repo string,
+	window TimeWindow) ([]*github.Event, time.Time, error) {
+	timeRange := window.Since.UTC().Format(searchTimeLayout) + ".." + window.Until.UTC().Format(searchTimeLayout)
+	coveredSince := window.Since
+	var events []*github.Event
+	addEvents := func(newEvents []*github.Event, err error) error {
+		events = append(events, newEvents...)
+		return err
+	}
+
+	created, covered, err := p.searchIssues(ctx, org, repo, "created:"+timeRange)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	coveredSince = latest(coveredSince, covered)
+	for _, issue := range created {
+		if err := addEvents(openedEvents(issue)); err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+
+	merged, covered, err := p.searchIssues(ctx, org, repo, "is:pr is:merged merged:"+timeRange)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	coveredSince = latest(coveredSince, covered)
+	closed, covered, err := p.searchIssues(ctx, org, repo, "is:issue closed:"+timeRange)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	coveredSince = latest(coveredSince, covered)
+	for _, issue := range append(merged, closed...) {
+		// Search results leave out who closed the item.
+		full, _, err := p.client.Issues.Get(ctx, org, repo, issue.GetNumber())
+		if err != nil {
+			return nil, time.Time{}, wrapAPIError(err, fmt.Sprintf("getting %s/%s#%d", org, repo, issue.GetNumber()))
+		}
+		if err := addEvents(closedEvents(full)); err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+
+	// Comments and reviews do not have a search qualifier, and an item
+	// commented on in the window can be updated any time after it, so this
+	// query has no upper bound.
+	updated, covered, err := p.searchIssues(ctx, org, repo, "updated:>="+window.Since.UTC().Format(searchTimeLayout))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	// Items missing from capped results may hold comments anywhere up to the
+	// oldest update returned, which can be after the window ends.
+	if covered.After(window.Until) {
+		covered = window.Until
+	}
+	coveredSince = latest(coveredSince, covered)
+	for _, issue := range updated {
+		comments, err := p.getIssueCommentsSince(ctx, org, repo, issue.GetNumber(), window.Since)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if err := addEvents(issueCommentEvents(issue, comments)); err != nil {
+			return nil, time.Time{}, err
+		}
+		if !issue.IsPullRequest() {
+			continue
+		}
+		reviews, err := p.getReviews(ctx, org, repo, issue.GetNumber())
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		reviewComments, err := p.getReviewComments(ctx, org, repo, issue.GetNumber(), window.Since)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if err := addEvents(reviewEvents(issue, reviews, reviewComments)); err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+	events = filterEvents(events, eventFilterSince(window.Since))
+	return events, coveredSince, nil
+}
+
+// searchIssues runs an issue search in repo, newest update first. When the
+// results are capped, every missing item was last updated before the oldest
+// returned one, so the returned time is how far back the results are
+// complete. Otherwise it is zero.
+func (p *GithubProxy) searchIssues(ctx context.Context, org, repo, qualifiers string) ([]*github.Issue, time.Time, error) {
+	query := fmt.Sprintf("repo:%s/%s %s", org, repo, qualifiers)
+	opts := &github.SearchOptions{
+		Sort:  "updated",
+		Order: "desc",
+		ListOptions: github.ListOptions{
+			PerPage: SEARCH_PER_PAGE,
+			Page:    1,
+		},
+	}
+	var issues []*github.Issue
+	total := 0
+	for {
+		result, resp, err := p.client.Search.Issues(ctx, query, opts)
+		if err != nil {
+			return nil, time.Time{}, wrapAPIError(err, fmt.Sprintf("searching issues '%s'", query))
+		}
+		issues = append(issues, result.Issues...)
+		total = result.GetTotal()
+		if resp.NextPage == 0 || len(issues) >= SEARCH_MAX_RESULTS {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	if len(issues) < total && len(issues) != 0 {
+		return issues, issues[len(issues)-1].GetUpdatedAt(), nil
+	}
+	return issues, time.Time{}, nil
+}
+
+func (p *GithubProxy) getReviewComments(ctx context.Context, org, repo string, prNum int, since time.Time) ([]*github.PullRequestComment, error) {
+	var comments []*github.PullRequestComment
+	opts := &github.PullRequestListCommentsOptions{
+		Since:       since,
+		ListOptions: github.ListOptions{PerPage: PRS_PER_PAGE, Page: 1},
+	}
+	for {
+		page, resp, err := p.client.PullRequests.ListComments(ctx, org, repo, prNum, opts)
+		if err != nil {
+			return nil, wrapAPIError(err, fmt.Sprintf("listing review comments for %s/%s#%d", org, repo, prNum))
+		}
+		comments = append(comments, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return comments, nil
+}
+
+func openedEvents(issue *github.Issue) ([]*github.Event, error) {
+	if issue.IsPullRequest() {
+		event, err := searchEvent("PullRequestEvent", issue.GetUser(), issue.GetCreatedAt(), &github.PullRequestEvent{
+			Action:      github.String("opened"),
+			Number:      issue.Number,
+			PullRequest: pullRequestFromIssue(issue, false),
+		})
+		return []*github.Event{event}, err
+	}
+	event, err := searchEvent("IssuesEvent", issue.GetUser(), issue.GetCreatedAt(), &github.IssuesEvent{
+		Action: github.String("opened"),
+		Issue:  issue,
+	})
+	return []*github.Event{event}, err
+}
+
+func closedEvents(issue *github.Issue) ([]*github.Event, error) {
+	if issue.IsPullRequest() {
+		event, err := searchEvent("PullRequestEvent", issue.GetClosedBy(), issue.GetClosedAt(), &github.PullRequestEvent{
+			Action:      github.String("closed"),
+			Number:      issue.Number,
+			PullRequest: pullRequestFromIssue(issue, true),
+		})
+		return []*github.Event{event}, err
+	}
+	event, err := searchEvent("IssuesEvent", issue.GetClosedBy(), issue.GetClosedAt(), &github.IssuesEvent{
+		Action: github.String("closed"),
+		Issue:  issue,
+	})
+	return []*github.Event{event}, err
+}
+
+func issueCommentEvents(issue *github.Issue, comments []*github.IssueComment) ([]*github.Event, error) {
+	var events []*github.Event
+	for _, comment := range comments {
+		event, err := searchEvent("IssueCommentEvent", comment.GetUser(), comment.GetCreatedAt(), &github.IssueCommentEvent{
+			Action:  github.String("created"),
+			Issue:   issue,
+			Comment: comment,
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func reviewEvents(issue *github.Issue, reviews []*github.PullRequestReview, comments []*github.PullRequestComment) ([]*github.Event, error) {
+	var events []*github.Event
+	pr := pullRequestFromIssue(issue, false)
+	for _, review := range reviews {
+		if strings.EqualFold(review.GetState(), "pending") {
+			continue
+		}
+		event, err := searchEvent("PullRequestReviewEvent", review.GetUser(), review.GetSubmittedAt(), &github.PullRequestReviewEvent{
+			Action:      github.String("created"),
+			Review:      review,
+			PullRequest: pr,
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	for _, comment := range comments {
+		event, err := searchEvent("PullRequestReviewCommentEvent", comment.GetUser(), comment.GetCreatedAt(), &github.PullRequestReviewCommentEvent{
+			Action:      github.String("created"),
+			Comment:     comment,
+			PullRequest: pr,
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func pullRequestFromIssue(issue *github.Issue, merged bool) *github.PullRequest {
+	return &github.PullRequest{
+		Number:  issue.Number,
+		Title:   issue.Title,
+		HTMLURL: issue.HTMLURL,
+		User:    issue.User,
+		Merged:  github.Bool(merged),
+	}
+}
+
+// searchEvent builds an event the way the Events API returns it, so reports
+// built from search results go through the same filters and aggregation.
+func searchEvent(eventType string, actor *github.User, at time.Time, payload interface{}) (*github.Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	raw := json.RawMessage(data)
+	return &github.Event{
+		Type:       github.String(eventType),
+		Actor:      actor,
+		CreatedAt:  &at,
+		RawPayload: &raw,
+	}, nil
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
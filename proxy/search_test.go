@@ -0,0 +1,57 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchEventsCapsCoverageAtWindowEnd(t *testing.T) {
+	defer func(max int) { SEARCH_MAX_RESULTS = max }(SEARCH_MAX_RESULTS)
+	SEARCH_MAX_RESULTS = 1
+	now := time.Now().UTC()
+	window := TimeWindow{Since: now.AddDate(0, -4, 0), Until: now.AddDate(0, -3, 0)}
+	commentsSince := ""
+	p := newTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/search/issues" && strings.Contains(r.URL.Query().Get("q"), "updated:"):
+			fmt.Fprintf(w, `{"total_count":5,"items":[%s]}`, issueJSON(3, now))
+		case r.URL.Path == "/search/issues":
+			fmt.Fprint(w, `{"total_count":0,"items":[]}`)
+		case r.URL.Path == "/repos/o/r/issues/3/comments":
+			commentsSince = r.URL.Query().Get("since")
+			fmt.Fprint(w, `[]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	_, coveredSince, err := p.getSearchEvents(context.Background(), "o", "r", window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !coveredSince.Equal(window.Until) {
+		t.Errorf("covered since %v, want the window end %v", coveredSince, window.Until)
+	}
+	if commentsSince != window.Since.Format(time.RFC3339) {
+		t.Errorf("comments listed since %q, want %q", commentsSince, window.Since.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,132 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v48/github"
+)
+
+const (
+	CI_SUCCESS string = "success"
+	CI_PENDING string = "pending"
+	CI_FAILURE string = "failure"
+	CI_NONE    string = "none"
+)
+
+type PullRequestDetails struct {
+	Status bool
+}
+
+type pullRequestStatus struct {
+	CommitStatus   string
+	CheckStatus    string
+	MergeableState string
+	BehindBy       int
+}
+
+func (p *GithubProxy) getPullRequestStatus(ctx context.Context, org, repo string, pr *github.PullRequest) (*pullRequestStatus, error) {
+	number := pr.GetNumber()
+	sha := pr.GetHead().GetSHA()
+	status := &pullRequestStatus{}
+
+	combined, _, err := p.client.Repositories.GetCombinedStatus(ctx, org, repo, sha, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, wrapAPIError(err, fmt.Sprintf("getting commit status for %s/%s#%d", org, repo, number))
+	}
+	status.CommitStatus = commitStatus(combined)
+
+	checkOpts := &github.ListCheckRunsOptions{
+		ListOptions: github.ListOptions{PerPage: 100, Page: 1},
+	}
+	var checkRuns []*github.CheckRun
+	for {
+		results, resp, err := p.client.Checks.ListCheckRunsForRef(ctx, org, repo, sha, checkOpts)
+		if err != nil {
+			return nil, wrapAPIError(err, fmt.Sprintf("listing check runs for %s/%s#%d", org, repo, number))
+		}
+		checkRuns = append(checkRuns, results.CheckRuns...)
+		if resp.NextPage == 0 {
+			break
+		}
+		checkOpts.Page = resp.NextPage
+	}
+	status.CheckStatus = checkStatus(checkRuns)
+
+	// mergeable_state is only returned when getting a single pull request.
+	full, _, err := p.client.PullRequests.Get(ctx, org, repo, number)
+	if err != nil {
+		return nil, wrapAPIError(err, fmt.Sprintf("getting pull request %s/%s#%d", org, repo, number))
+	}
+	status.MergeableState = full.GetMergeableState()
+	if status.MergeableState == "" {
+		status.MergeableState = "unknown"
+	}
+
+	comparison, _, err := p.client.Repositories.CompareCommits(ctx, org, repo, pr.GetBase().GetRef(), sha, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return nil, wrapAPIError(err, fmt.Sprintf("comparing %s/%s#%d with its base", org, repo, number))
+	}
+	status.BehindBy = comparison.GetBehindBy()
+	return status, nil
+}
+
+func commitStatus(combined *github.CombinedStatus) string {
+	if combined.GetTotalCount() == 0 {
+		return CI_NONE
+	}
+	switch combined.GetState() {
+	case "success":
+		return CI_SUCCESS
+	case "pending":
+		return CI_PENDING
+	default:
+		return CI_FAILURE
+	}
+}
+
+func checkStatus(checkRuns []*github.CheckRun) string {
+	if len(checkRuns) == 0 {
+		return CI_NONE
+	}
+	result := CI_SUCCESS
+	for _, run := range checkRuns {
+		if run.GetStatus() != "completed" {
+			result = CI_PENDING
+			continue
+		}
+		switch run.GetConclusion() {
+		case "failure", "timed_out", "cancelled", "action_required", "startup_failure":
+			return CI_FAILURE
+		}
+	}
+	return result
+}
+
+// combineCIStatus reports the worst of the commit status and check runs.
+func combineCIStatus(statuses ...string) string {
+	rank := map[string]int{CI_NONE: 0, CI_SUCCESS: 1, CI_PENDING: 2, CI_FAILURE: 3}
+	combined := CI_NONE
+	for _, status := range statuses {
+		if rank[status] > rank[combined] {
+			combined = status
+		}
+	}
+	return combined
+}
@@ -0,0 +1,405 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	storeFile = ".ghmt.db"
+	// STORE_LOCK_TIMEOUT bounds the wait for another ghmt process holding
+	// the store.
+	STORE_LOCK_TIMEOUT time.Duration = 5 * time.Second
+)
+
+var (
+	storeEventsBucket    = []byte("events")
+	storeWatermarkKey    = []byte("watermark")
+	storeCoveredSinceKey = []byte("covered_since")
+	storeSyncedAtKey     = []byte("synced_at")
+	storeGapsKey         = []byte("gaps")
+)
+
+type SyncReport struct {
+	Org   string     `json:"org"`
+	Repos []RepoSync `json:"repos"`
+}
+
+// RepoSync describes one repo of a sync. On the first sync CoveredSince is
+// where the stored history starts. On later syncs Gap is set when the
+// Events API no longer reached back to the previous sync, and CoveredSince
+// is then the oldest time the new events cover.
+type RepoSync struct {
+	Repo         string     `json:"repo"`
+	Added        int        `json:"added"`
+	First        bool       `json:"first"`
+	Gap          bool       `json:"gap"`
+	CoveredSince *time.Time `json:"covered_since,omitempty"`
+}
+
+// eventStore keeps events in a bbolt database with a bucket per repo. Events
+// are keyed by creation time and ID, so they are deduplicated and read back
+// in time order.
+type eventStore struct {
+	db *bolt.DB
+}
+
+func DefaultStorePath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, storeFile)
+}
+
+func openEventStore(path string, readOnly bool) (*eventStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("No event store path is configured")
+	}
+	if readOnly {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("No event store at %s, run ghmt sync first", path)
+		}
+	} else if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: STORE_LOCK_TIMEOUT, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open event store %s: %v", path, err)
+	}
+	return &eventStore{db: db}, nil
+}
+
+func (s *eventStore) close() error {
+	return s.db.Close()
+}
+
+func repoBucketName(org, repo string) []byte {
+	return []byte(org + "/" + repo)
+}
+
+func eventKey(event *github.Event) []byte {
+	key := make([]byte, 8, 8+len(event.GetID()))
+	binary.BigEndian.PutUint64(key, uint64(event.GetCreatedAt().UnixNano()))
+	return append(key, event.GetID()...)
+}
+
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
+
+// watermark is the newest event ID stored for the repo, empty before the
+// first sync.
+func (s *eventStore) watermark(org, repo string) (string, error) {
+	var watermark string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(repoBucketName(org, repo)); bucket != nil {
+			watermark = string(bucket.Get(storeWatermarkKey))
+		}
+		return nil
+	})
+	return watermark, err
+}
+
+// putEvents stores the events not already stored and moves the watermark
+// forward. The first sync of a repo also records where its history starts,
+// later syncs whose events start after coveredSince record the range from
+// the previous sync as a gap.
+func (s *eventStore) putEvents(org, repo string, events []*github.Event, coveredSince, syncedAt time.Time) (int, bool, error) {
+	added := 0
+	gap := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(repoBucketName(org, repo))
+		if err != nil {
+			return err
+		}
+		eventsBucket, err := bucket.CreateBucketIfNotExists(storeEventsBucket)
+		if err != nil {
+			return err
+		}
+		watermark := string(bucket.Get(storeWatermarkKey))
+		if watermark != "" && !coveredSince.IsZero() {
+			if gap, err = putGap(bucket, eventsBucket, coveredSince); err != nil {
+				return err
+			}
+		}
+		for _, event := range events {
+			key := eventKey(event)
+			if eventsBucket.Get(key) != nil {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			if err := eventsBucket.Put(key, data); err != nil {
+				return err
+			}
+			added++
+			if watermark == "" || eventIDAfter(event.GetID(), watermark) {
+				watermark = event.GetID()
+			}
+		}
+		if watermark != "" {
+			if err := bucket.Put(storeWatermarkKey, []byte(watermark)); err != nil {
+				return err
+			}
+		}
+		if err := bucket.Put(storeSyncedAtKey, []byte(syncedAt.UTC().Format(time.RFC3339Nano))); err != nil {
+			return err
+		}
+		if bucket.Get(storeCoveredSinceKey) == nil {
+			return bucket.Put(storeCoveredSinceKey, []byte(coveredSince.UTC().Format(time.RFC3339Nano)))
+		}
+		return nil
+	})
+	return added, gap, err
+}
+
+// putGap records that events from the previous sync until coveredSince may
+// be missing. Stores written before the sync time was kept start the gap at
+// the newest stored event.
+func putGap(bucket, eventsBucket *bolt.Bucket, coveredSince time.Time) (bool, error) {
+	gap := TimeWindow{Until: coveredSince.UTC()}
+	if synced, err := time.Parse(time.RFC3339Nano, string(bucket.Get(storeSyncedAtKey))); err == nil {
+		gap.Since = synced
+	} else if key, _ := eventsBucket.Cursor().Last(); key != nil {
+		gap.Since = time.Unix(0, int64(binary.BigEndian.Uint64(key[:8]))).UTC()
+	}
+	if !gap.Since.Before(gap.Until) {
+		return false, nil
+	}
+	gaps, err := storedGaps(bucket)
+	if err != nil {
+		return false, err
+	}
+	data, err := json.Marshal(append(gaps, gap))
+	if err != nil {
+		return false, err
+	}
+	return true, bucket.Put(storeGapsKey, data)
+}
+
+func storedGaps(bucket *bolt.Bucket) ([]TimeWindow, error) {
+	var gaps []TimeWindow
+	if data := bucket.Get(storeGapsKey); data != nil {
+		if err := json.Unmarshal(data, &gaps); err != nil {
+			return nil, err
+		}
+	}
+	return gaps, nil
+}
+
+// syncedAt is when the repo was last synced, the zero time for stores
+// written before the sync time was kept.
+func (s *eventStore) syncedAt(org, repo string) (time.Time, error) {
+	var syncedAt time.Time
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(repoBucketName(org, repo)); bucket != nil {
+			syncedAt, _ = time.Parse(time.RFC3339Nano, string(bucket.Get(storeSyncedAtKey)))
+		}
+		return nil
+	})
+	return syncedAt, err
+}
+
+// eventsBetween reads the stored events created in [since, until), and the
+// oldest time the stored history covers without a gap if that is after
+// since.
+func (s *eventStore) eventsBetween(org, repo string, since, until time.Time) ([]*github.Event, time.Time, error) {
+	var events []*github.Event
+	coveredSince := since
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(repoBucketName(org, repo))
+		if bucket == nil {
+			return fmt.Errorf("No events stored for %s/%s, run ghmt sync first", org, repo)
+		}
+		if stored, err := time.Parse(time.RFC3339Nano, string(bucket.Get(storeCoveredSinceKey))); err == nil && stored.After(since) {
+			coveredSince = stored
+		}
+		gaps, err := storedGaps(bucket)
+		if err != nil {
+			return err
+		}
+		for _, gap := range gaps {
+			if gap.Since.Before(until) && gap.Until.After(coveredSince) {
+				coveredSince = gap.Until
+			}
+		}
+		eventsBucket := bucket.Bucket(storeEventsBucket)
+		if eventsBucket == nil {
+			return nil
+		}
+		end := timeKey(until)
+		cursor := eventsBucket.Cursor()
+		for key, value := cursor.Seek(timeKey(since)); key != nil && bytes.Compare(key[:8], end) < 0; key, value = cursor.Next() {
+			event := &github.Event{}
+			if err := json.Unmarshal(value, event); err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return events, coveredSince, nil
+}
+
+// repos lists the stored repos of an org.
+func (s *eventStore) repos(org string) ([]string, error) {
+	var repos []string
+	prefix := org + "/"
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			if strings.HasPrefix(string(name), prefix) {
+				repos = append(repos, strings.TrimPrefix(string(name), prefix))
+			}
+			return nil
+		})
+	})
+	return repos, err
+}
+
+// Sync pulls the events of each repo that are newer than the newest stored
+// one into the local event store.
+func (p *GithubProxy) Sync(org string, repos []string) (*SyncReport, error) {
+	store, err := openEventStore(p.storePath, false)
+	if err != nil {
+		return nil, err
+	}
+	defer store.close()
+	ctx := context.Background()
+	report := &SyncReport{
+		Org:   org,
+		Repos: make([]RepoSync, len(repos)),
+	}
+	err = p.forEachRepo(ctx, repos, func(ctx context.Context, i int, repo string) error {
+		watermark, err := store.watermark(org, repo)
+		if err != nil {
+			return err
+		}
+		syncedAt := time.Now()
+		events, coveredSince, err := p.getEventsSince(ctx, org, repo, time.Time{}, watermark)
+		if err != nil {
+			return err
+		}
+		added, gap, err := store.putEvents(org, repo, events, coveredSince, syncedAt)
+		if err != nil {
+			return fmt.Errorf("Failed to store events for %s/%s: %v", org, repo, err)
+		}
+		sync := RepoSync{Repo: repo, Added: added, First: watermark == "", Gap: gap}
+		if !coveredSince.IsZero() && (sync.First || sync.Gap) {
+			coveredSince = coveredSince.In(p.location)
+			sync.CoveredSince = &coveredSince
+		}
+		report.Repos[i] = sync
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(report.Repos, func(i, j int) bool {
+		return report.Repos[i].Repo < report.Repos[j].Repo
+	})
+	return report, nil
+}
+
+// GetEventsFromStore builds the same report as GetEvents from the events
+// saved by Sync, over any window the store covers, without calling the API.
+// Repos whose window reaches past their last sync are marked as truncated
+// with the time of that sync.
+func (p *GithubProxy) GetEventsFromStore(org string, repos []string, spec TimeWindowSpec, filter EventFilter) (*EventReport, error) {
+	store, err := openEventStore(p.storePath, true)
+	if err != nil {
+		return nil, err
+	}
+	defer store.close()
+	source := func(ctx context.Context, org, repo string, window TimeWindow) ([]*github.Event, time.Time, error) {
+		return store.eventsBetween(org, repo, window.Since, window.Until)
+	}
+	report, err := p.getEventReport(org, repos, spec, filter, source)
+	if err != nil {
+		return nil, err
+	}
+	for i := range report.Repos {
+		syncedAt, err := store.syncedAt(org, report.Repos[i].Repo)
+		if err != nil {
+			return nil, err
+		}
+		if !syncedAt.IsZero() && syncedAt.Before(report.Until) {
+			syncedAt = syncedAt.In(report.Since.Location())
+			report.Repos[i].Truncated = true
+			report.Repos[i].CoveredUntil = &syncedAt
+		}
+	}
+	return report, nil
+}
+
+// GetStoredReposForOrg lists the repos of an org in the event store. Only the
+// name filters apply, the others need repository metadata from the API.
+func (p *GithubProxy) GetStoredReposForOrg(org string, filter RepoFilter) ([]string, error) {
+	if filter.ExcludeArchived || filter.ExcludeForks || len(filter.Topics) != 0 ||
+		len(filter.Languages) != 0 || (filter.Visibility != "" && filter.Visibility != "all") {
+		return nil, fmt.Errorf("Only the include and exclude repo filters can be used with the event store")
+	}
+	include, err := newNameMatchers(filter.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := newNameMatchers(filter.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	store, err := openEventStore(p.storePath, true)
+	if err != nil {
+		return nil, err
+	}
+	defer store.close()
+	stored, err := store.repos(org)
+	if err != nil {
+		return nil, err
+	}
+	var repos = []string{}
+	for _, repo := range stored {
+		if len(include) != 0 && !matchAny(include, repo) {
+			continue
+		}
+		if matchAny(exclude, repo) {
+			continue
+		}
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	return repos, nil
+}
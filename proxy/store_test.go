@@ -0,0 +1,93 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func eventJSON(id string, created time.Time) string {
+	return fmt.Sprintf(`{"id":%q,"type":"IssuesEvent","created_at":%q,"payload":{"action":"opened","issue":%s}}`,
+		id, created.Format(time.RFC3339), issueJSON(1, created))
+}
+
+func TestSyncReportsGapsOnlyForMissedEvents(t *testing.T) {
+	defer func(max int) { EVENTS_API_MAX_EVENTS = max }(EVENTS_API_MAX_EVENTS)
+	EVENTS_API_MAX_EVENTS = 2
+	now := time.Now().UTC()
+	var page []string
+	p := newTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/o/r/events" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(page, ","))
+	}))
+	p.storePath = filepath.Join(t.TempDir(), "events.db")
+
+	// The events of the last sync are stamped after the earlier syncs, so
+	// with only two of them returned the ones in between are a gap.
+	syncs := []struct {
+		name  string
+		page  []string
+		first bool
+		gap   bool
+	}{
+		{"first sync", []string{eventJSON("10", now.Add(-2*time.Hour))}, true, false},
+		{"dormant repo", nil, false, false},
+		{"watermark reached", []string{eventJSON("11", now.Add(-time.Hour)), eventJSON("10", now.Add(-2*time.Hour))}, false, false},
+		{"watermark dropped", []string{eventJSON("13", now.Add(2*time.Hour)), eventJSON("12", now.Add(time.Hour))}, false, true},
+	}
+	for _, sync := range syncs {
+		page = sync.page
+		report, err := p.Sync("o", []string{"r"})
+		if err != nil {
+			t.Fatalf("%s: %v", sync.name, err)
+		}
+		repo := report.Repos[0]
+		if repo.First != sync.first || repo.Gap != sync.gap {
+			t.Errorf("%s: first = %v gap = %v, want first = %v gap = %v", sync.name, repo.First, repo.Gap, sync.first, sync.gap)
+		}
+	}
+
+	report, err := p.GetEventsFromStore("o", []string{"r"}, TimeWindowSpec{
+		From: now.Add(-3 * time.Hour).Format(time.RFC3339),
+		To:   now.Add(-30 * time.Minute).Format(time.RFC3339),
+	}, EventFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo := report.Repos[0]; repo.Truncated {
+		t.Errorf("window before the gap truncated %s", repo.Coverage())
+	}
+
+	report, err = p.GetEventsFromStore("o", []string{"r"}, TimeWindowSpec{Hours: 30}, EventFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := report.Repos[0]
+	if !repo.Truncated || repo.CoveredSince == nil || !repo.CoveredSince.Equal(now.Add(time.Hour).Truncate(time.Second)) {
+		t.Errorf("window over the gap truncated = %v covering %s, want it covered since the gap end", repo.Truncated, repo.Coverage())
+	}
+	if repo.CoveredUntil == nil || !repo.CoveredUntil.Before(report.Until) {
+		t.Errorf("window past the last sync covers %s, want it covered until the last sync", repo.Coverage())
+	}
+}
@@ -0,0 +1,111 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+)
+
+const (
+	ACTION_OPENED     string = "opened"
+	ACTION_COMMENT    string = "comment"
+	ACTION_REVIEW     string = "review"
+	ACTION_COMMIT     string = "commit"
+	ACTION_FORCE_PUSH string = "force_push"
+)
+
+type Action struct {
+	Kind  string
+	Actor string
+	At    time.Time
+}
+
+type timelineSummary struct {
+	LastAction  Action
+	LastComment *Action
+	Comments    int
+}
+
+func (p *GithubProxy) getTimeline(ctx context.Context, org, repo string, number int) ([]*github.Timeline, error) {
+	var timeline []*github.Timeline
+	opts := &github.ListOptions{PerPage: PRS_PER_PAGE, Page: 1}
+	for {
+		page, resp, err := p.client.Issues.ListIssueTimeline(ctx, org, repo, number, opts)
+		if err != nil {
+			return nil, wrapAPIError(err, fmt.Sprintf("listing timeline for %s/%s#%d", org, repo, number))
+		}
+		timeline = append(timeline, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return timeline, nil
+}
+
+// summarizeTimeline finds the latest comment, review, commit or force push,
+// falling back to the item being opened when there was no activity since.
+func summarizeTimeline(author string, createdAt time.Time, timeline []*github.Timeline) timelineSummary {
+	summary := timelineSummary{
+		LastAction: Action{Kind: ACTION_OPENED, Actor: author, At: createdAt},
+	}
+	for _, event := range timeline {
+		action, ok := timelineAction(event)
+		if !ok {
+			continue
+		}
+		if action.Kind == ACTION_COMMENT {
+			summary.Comments++
+			if summary.LastComment == nil || action.At.After(summary.LastComment.At) {
+				comment := action
+				summary.LastComment = &comment
+			}
+		}
+		if action.At.After(summary.LastAction.At) {
+			summary.LastAction = action
+		}
+	}
+	return summary
+}
+
+func timelineAction(event *github.Timeline) (Action, bool) {
+	switch event.GetEvent() {
+	case "commented":
+		return Action{Kind: ACTION_COMMENT, Actor: timelineActor(event), At: event.GetCreatedAt()}, true
+	case "reviewed":
+		return Action{Kind: ACTION_REVIEW, Actor: timelineActor(event), At: event.GetSubmittedAt()}, true
+	case "committed":
+		if event.Committer == nil || event.Committer.Date == nil {
+			return Action{}, false
+		}
+		return Action{Kind: ACTION_COMMIT, Actor: event.GetAuthor().GetName(), At: event.GetCommitter().GetDate()}, true
+	case "head_ref_force_pushed":
+		return Action{Kind: ACTION_FORCE_PUSH, Actor: timelineActor(event), At: event.GetCreatedAt()}, true
+	}
+	return Action{}, false
+}
+
+func timelineActor(event *github.Timeline) string {
+	if login := event.GetActor().GetLogin(); login != "" {
+		return login
+	}
+	return event.GetUser().GetLogin()
+}
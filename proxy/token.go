@@ -0,0 +1,138 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	tokenFile       = ".ghmt"
+	tokenEnvVars    = []string{"GHMT_TOKEN", "GITHUB_TOKEN"}
+	GH_DEFAULT_HOST = "github.com"
+)
+
+type TokenOptions struct {
+	Token     string
+	TokenFile string
+	Host      string
+}
+
+type Token struct {
+	Value  string
+	Source string
+}
+
+func ResolveToken(opts TokenOptions) (Token, error) {
+	if opts.Token != "" {
+		return Token{Value: opts.Token, Source: "--token flag"}, nil
+	}
+	if opts.TokenFile != "" {
+		token, err := readTokenFile(opts.TokenFile)
+		if err != nil {
+			return Token{}, &MissingTokenError{Source: opts.TokenFile, Err: err}
+		}
+		return Token{Value: token, Source: "--token-file " + opts.TokenFile}, nil
+	}
+	for _, envVar := range tokenEnvVars {
+		if token := strings.TrimSpace(os.Getenv(envVar)); token != "" {
+			return Token{Value: token, Source: envVar + " environment variable"}, nil
+		}
+	}
+	if hostsFile := getGhHostsFileName(); hostsFile != "" {
+		host := opts.Host
+		if host == "" {
+			host = GH_DEFAULT_HOST
+		}
+		token, err := readGhHostsToken(hostsFile, host)
+		if err == nil && token != "" {
+			return Token{Value: token, Source: "gh CLI config " + hostsFile}, nil
+		}
+	}
+	token, err := readTokenFile(getTokenFileName())
+	if err != nil {
+		return Token{}, &MissingTokenError{
+			Source: "--token, --token-file, GHMT_TOKEN, GITHUB_TOKEN, gh CLI config or " + getTokenFileName(),
+			Err:    err,
+		}
+	}
+	return Token{Value: token, Source: getTokenFileName()}, nil
+}
+
+func getTokenFileName() string {
+	homeDir, _ := os.UserHomeDir()
+	return homeDir + "/" + tokenFile
+}
+
+func readTokenFile(fileName string) (string, error) {
+	token, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return "", err
+	}
+	cleanToken := strings.TrimSpace(strings.Replace(string(token), "\n", "", -1))
+	if cleanToken == "" {
+		return "", errors.New("token file is empty")
+	}
+	return cleanToken, nil
+}
+
+func getGhHostsFileName() string {
+	if configDir := os.Getenv("GH_CONFIG_DIR"); configDir != "" {
+		return filepath.Join(configDir, "hosts.yml")
+	}
+	if configDir := os.Getenv("XDG_CONFIG_HOME"); configDir != "" {
+		return filepath.Join(configDir, "gh", "hosts.yml")
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".config", "gh", "hosts.yml")
+}
+
+// readGhHostsToken pulls the oauth_token for host out of the gh CLI hosts.yml,
+// which is a map of host name to settings, without a full YAML parser.
+func readGhHostsToken(fileName, host string) (string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	inHost := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			inHost = strings.TrimSuffix(trimmed, ":") == host
+			continue
+		}
+		if inHost && strings.HasPrefix(trimmed, "oauth_token:") {
+			token := strings.TrimSpace(strings.TrimPrefix(trimmed, "oauth_token:"))
+			return strings.Trim(token, "\"'"), nil
+		}
+	}
+	return "", scanner.Err()
+}
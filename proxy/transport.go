@@ -0,0 +1,164 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	MAX_RETRIES         int           = 5
+	RETRY_BASE_DELAY    time.Duration = time.Second
+	RETRY_MAX_DELAY     time.Duration = time.Minute
+	RATE_LIMIT_MAX_WAIT time.Duration = time.Hour
+	// GitHub asks clients to wait at least a minute on secondary rate limits
+	// that come without a Retry-After header.
+	SECONDARY_RATE_LIMIT_DELAY time.Duration = time.Minute
+)
+
+// retryTransport waits out primary and secondary rate limits and retries
+// transient failures with jittered exponential backoff.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+func newRetryTransport(base http.RoundTripper) *retryTransport {
+	return &retryTransport{
+		base:       base,
+		maxRetries: MAX_RETRIES,
+		sleep:      sleepContext,
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+		resp, err := t.base.RoundTrip(attemptReq)
+		if req.Context().Err() != nil {
+			return resp, err
+		}
+		delay, retry := retryDelay(resp, err, attempt)
+		// A body that can't be rewound was used up by this attempt.
+		if !retry || attempt >= t.maxRetries || !rewindable(req) {
+			return resp, err
+		}
+		if resp != nil {
+			ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func retryDelay(resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if err != nil {
+		return backoff(attempt), true
+	}
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		return rateLimitDelay(resp)
+	case resp.StatusCode >= http.StatusInternalServerError && resp.StatusCode != http.StatusNotImplemented:
+		return backoff(attempt), true
+	}
+	return 0, false
+}
+
+func rateLimitDelay(resp *http.Response) (time.Duration, bool) {
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return capRateLimitWait(time.Duration(seconds) * time.Second)
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		// A second of slack for clock skew with the API servers.
+		return capRateLimitWait(time.Until(time.Unix(reset, 0)) + time.Second)
+	}
+	if isSecondaryRateLimit(resp) {
+		return capRateLimitWait(SECONDARY_RATE_LIMIT_DELAY)
+	}
+	return 0, false
+}
+
+func capRateLimitWait(wait time.Duration) (time.Duration, bool) {
+	if wait > RATE_LIMIT_MAX_WAIT {
+		return 0, false
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
+
+// isSecondaryRateLimit peeks at the body of a 403 and leaves it readable for
+// the caller.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	message := strings.ToLower(string(body))
+	return strings.Contains(message, "secondary rate limit") ||
+		strings.Contains(message, "abuse detection")
+}
+
+func backoff(attempt int) time.Duration {
+	delay := RETRY_BASE_DELAY << uint(attempt)
+	if delay > RETRY_MAX_DELAY || delay <= 0 {
+		delay = RETRY_MAX_DELAY
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
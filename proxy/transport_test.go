@@ -0,0 +1,166 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testResponse(status int, header map[string]string, body string) *http.Response {
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+	for name, value := range header {
+		resp.Header.Set(name, value)
+	}
+	return resp
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name  string
+		resp  *http.Response
+		err   error
+		retry bool
+	}{
+		{"network error", nil, errors.New("connection reset"), true},
+		{"ok", testResponse(http.StatusOK, nil, ""), nil, false},
+		{"not found", testResponse(http.StatusNotFound, nil, ""), nil, false},
+		{"bad gateway", testResponse(http.StatusBadGateway, nil, ""), nil, true},
+		{"not implemented", testResponse(http.StatusNotImplemented, nil, ""), nil, false},
+		{"forbidden", testResponse(http.StatusForbidden, nil, `{"message":"Resource not accessible by integration"}`), nil, false},
+		{"too many requests", testResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "3"}, ""), nil, true},
+	}
+	for _, test := range tests {
+		delay, retry := retryDelay(test.resp, test.err, 2)
+		if retry != test.retry {
+			t.Errorf("%s: retry = %v, want %v", test.name, retry, test.retry)
+		}
+		if !retry && delay != 0 {
+			t.Errorf("%s: delay = %v without a retry", test.name, delay)
+		}
+	}
+}
+
+func TestBackoffIsJitteredAndCapped(t *testing.T) {
+	for attempt := 0; attempt < 40; attempt++ {
+		limit := RETRY_BASE_DELAY << uint(attempt)
+		if limit > RETRY_MAX_DELAY || limit <= 0 {
+			limit = RETRY_MAX_DELAY
+		}
+		if delay := backoff(attempt); delay < limit/2 || delay > limit {
+			t.Errorf("backoff(%d) = %v, want between %v and %v", attempt, delay, limit/2, limit)
+		}
+	}
+}
+
+func TestRateLimitDelay(t *testing.T) {
+	reset := func(d time.Duration) string {
+		return strconv.FormatInt(time.Now().Add(d).Unix(), 10)
+	}
+	tests := []struct {
+		name  string
+		resp  *http.Response
+		min   time.Duration
+		max   time.Duration
+		retry bool
+	}{
+		{"retry after", testResponse(http.StatusForbidden, map[string]string{"Retry-After": "30"}, ""),
+			30 * time.Second, 30 * time.Second, true},
+		{"retry after beyond the max wait", testResponse(http.StatusForbidden, map[string]string{"Retry-After": "7200"}, ""),
+			0, 0, false},
+		{"primary limit", testResponse(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset(10 * time.Minute)}, ""),
+			9 * time.Minute, 11 * time.Minute, true},
+		{"primary limit already reset", testResponse(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset(-time.Minute)}, ""),
+			0, 0, true},
+		{"primary limit without reset", testResponse(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0"}, ""),
+			0, 0, false},
+		{"secondary limit", testResponse(http.StatusForbidden, nil, `{"message":"You have exceeded a secondary rate limit."}`),
+			SECONDARY_RATE_LIMIT_DELAY, SECONDARY_RATE_LIMIT_DELAY, true},
+		{"abuse detection", testResponse(http.StatusForbidden, nil, `{"message":"You have triggered an abuse detection mechanism."}`),
+			SECONDARY_RATE_LIMIT_DELAY, SECONDARY_RATE_LIMIT_DELAY, true},
+		{"permission denied", testResponse(http.StatusForbidden, nil, `{"message":"Must have admin rights to Repository."}`),
+			0, 0, false},
+	}
+	for _, test := range tests {
+		delay, retry := rateLimitDelay(test.resp)
+		if retry != test.retry {
+			t.Errorf("%s: retry = %v, want %v", test.name, retry, test.retry)
+		}
+		if delay < test.min || delay > test.max {
+			t.Errorf("%s: delay = %v, want between %v and %v", test.name, delay, test.min, test.max)
+		}
+	}
+}
+
+func TestSecondaryRateLimitLeavesBodyReadable(t *testing.T) {
+	const message = `{"message":"You have exceeded a secondary rate limit."}`
+	resp := testResponse(http.StatusForbidden, nil, message)
+	if !isSecondaryRateLimit(resp) {
+		t.Fatal("secondary rate limit not detected")
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil || string(body) != message {
+		t.Errorf("body = %q, %v after the check, want %q", body, err, message)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryTransportRewindsBodies(t *testing.T) {
+	var bodies []string
+	transport := newRetryTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, _ := ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		return testResponse(http.StatusBadGateway, nil, "bad gateway"), nil
+	}))
+	transport.maxRetries = 2
+	transport.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	req, _ := http.NewRequest(http.MethodPost, "https://api.github.com/graphql", strings.NewReader("query"))
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(bodies, ",") != "query,query,query" {
+		t.Errorf("sent bodies %q, want the full body on every attempt", bodies)
+	}
+
+	bodies = nil
+	req, _ = http.NewRequest(http.MethodPost, "https://api.github.com/graphql", ioutil.NopCloser(strings.NewReader("query")))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if len(bodies) != 1 || resp.StatusCode != http.StatusBadGateway || string(body) != "bad gateway" {
+		t.Errorf("sent %d attempts returning %d %q, want the first response of a body that can't be rewound",
+			len(bodies), resp.StatusCode, body)
+	}
+}
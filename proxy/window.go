@@ -0,0 +1,265 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DAY_LAYOUT   string = "2006-01-02"
+	MONTH_LAYOUT string = "2006-01"
+)
+
+var lastPattern = regexp.MustCompile(`^(\d+)([hdw])$`)
+var weekPattern = regexp.MustCompile(`^(\d{4})-W(\d{2})$`)
+
+// TimeWindowSpec holds the time flags of the events command. Exactly one of
+// Since, Hours, Date, From, Last, Week and Month is set; To may only be
+// combined with From.
+type TimeWindowSpec struct {
+	Since string
+	Hours int
+	Date  string
+	From  string
+	To    string
+	Last  string
+	Week  string
+	Month string
+}
+
+// TimeWindow is a resolved [Since, Until) range. Label names calendar
+// windows (a day, ISO week or month) and is empty otherwise.
+type TimeWindow struct {
+	Since time.Time
+	Until time.Time
+	Label string
+}
+
+func (s TimeWindowSpec) set() []string {
+	var names []string
+	if s.Since != "" {
+		names = append(names, "since")
+	}
+	if s.Hours != 0 {
+		names = append(names, "hours")
+	}
+	if s.Date != "" {
+		names = append(names, "date")
+	}
+	if s.From != "" {
+		names = append(names, "from")
+	}
+	if s.Last != "" {
+		names = append(names, "last")
+	}
+	if s.Week != "" {
+		names = append(names, "week")
+	}
+	if s.Month != "" {
+		names = append(names, "month")
+	}
+	return names
+}
+
+func (s TimeWindowSpec) Validate() error {
+	names := s.set()
+	if s.To != "" && s.From == "" {
+		return fmt.Errorf("Cannot have to set without from")
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("No time flag [since,hours,date,from,last,week,month] is set")
+	}
+	if len(names) > 1 {
+		return fmt.Errorf("Cannot have %s set together", strings.Join(names, " and "))
+	}
+	if s.Hours < 0 {
+		return fmt.Errorf("Hours must be positive")
+	}
+	return nil
+}
+
+// Resolve turns the spec into a window. Relative flags and a from without to
+// end at now, while a date, week or month covers the whole calendar period
+// even when it ends in the future. Dates and timestamps without an offset
+// are read in loc.
+func (s TimeWindowSpec) Resolve(now time.Time, loc *time.Location) (TimeWindow, error) {
+	if err := s.Validate(); err != nil {
+		return TimeWindow{}, err
+	}
+	now = now.In(loc)
+	switch {
+	case s.Hours != 0:
+		return TimeWindow{Since: now.Add(time.Hour * time.Duration(-1*s.Hours)), Until: now}, nil
+	case s.Since != "":
+		since, err := parseTimestamp(s.Since, loc)
+		if err != nil {
+			return TimeWindow{}, &InvalidTimeError{Value: s.Since, Format: "RFC3339 e.g. 2006-01-02T15:04:05Z", Err: err}
+		}
+		return TimeWindow{Since: since, Until: now}, nil
+	case s.Date != "":
+		day, err := time.ParseInLocation(DAY_LAYOUT, s.Date, loc)
+		if err != nil {
+			return TimeWindow{}, &InvalidTimeError{Value: s.Date, Format: "YYYY-MM-DD e.g. 2006-01-02", Err: err}
+		}
+		return TimeWindow{Since: day, Until: day.AddDate(0, 0, 1), Label: s.Date}, nil
+	case s.From != "":
+		return resolveRange(s.From, s.To, now, loc)
+	case s.Last != "":
+		since, err := ParseLast(s.Last, now)
+		if err != nil {
+			return TimeWindow{}, err
+		}
+		return TimeWindow{Since: since, Until: now}, nil
+	case s.Week != "":
+		monday, err := parseISOWeek(s.Week, loc)
+		if err != nil {
+			return TimeWindow{}, err
+		}
+		return TimeWindow{Since: monday, Until: monday.AddDate(0, 0, 7), Label: s.Week}, nil
+	default:
+		month, err := time.ParseInLocation(MONTH_LAYOUT, s.Month, loc)
+		if err != nil {
+			return TimeWindow{}, &InvalidTimeError{Value: s.Month, Format: "YYYY-MM e.g. 2006-01", Err: err}
+		}
+		return TimeWindow{Since: month, Until: month.AddDate(0, 1, 0), Label: s.Month}, nil
+	}
+}
+
+// resolveRange accepts dates or timestamps for both ends. A date given for
+// to includes that whole day, and a missing to means now.
+func resolveRange(from, to string, now time.Time, loc *time.Location) (TimeWindow, error) {
+	const rangeFormat = "YYYY-MM-DD or RFC3339 e.g. 2006-01-02T15:04:05Z"
+	window := TimeWindow{Until: now}
+	since, err := ParseDayOrTimestamp(from, loc)
+	if err != nil {
+		return TimeWindow{}, &InvalidTimeError{Value: from, Format: rangeFormat, Err: err}
+	}
+	window.Since = since
+	if to != "" {
+		until, err := ParseDayOrTimestamp(to, loc)
+		if err != nil {
+			return TimeWindow{}, &InvalidTimeError{Value: to, Format: rangeFormat, Err: err}
+		}
+		if len(to) == len(DAY_LAYOUT) {
+			until = until.AddDate(0, 0, 1)
+		}
+		window.Until = until
+	}
+	if !window.Since.Before(window.Until) {
+		return TimeWindow{}, &InvalidTimeError{Value: from, Format: "a time before " + window.Until.Format(time.RFC3339)}
+	}
+	return window, nil
+}
+
+// parseTimestamp accepts RFC3339 with any offset, or a timestamp without an
+// offset which is read in loc.
+func parseTimestamp(value string, loc *time.Location) (time.Time, error) {
+	const localLayout = "2006-01-02T15:04:05"
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		var localErr error
+		t, localErr = time.ParseInLocation(localLayout, value, loc)
+		if localErr != nil {
+			return time.Time{}, err
+		}
+	}
+	return t.In(loc), nil
+}
+
+// ParseDayOrTimestamp accepts a YYYY-MM-DD date, the start of that day in
+// loc, or any timestamp parseTimestamp accepts.
+func ParseDayOrTimestamp(value string, loc *time.Location) (time.Time, error) {
+	if day, err := time.ParseInLocation(DAY_LAYOUT, value, loc); err == nil {
+		return day, nil
+	}
+	return parseTimestamp(value, loc)
+}
+
+// ParseLast reads durations such as 12h, 3d or 2w back from now. Days and
+// weeks are calendar days in now's location.
+func ParseLast(value string, now time.Time) (time.Time, error) {
+	count, unit, err := parseLastCount(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch unit {
+	case "h":
+		return now.Add(time.Hour * time.Duration(-1*count)), nil
+	case "d":
+		return now.AddDate(0, 0, -1*count), nil
+	default:
+		return now.AddDate(0, 0, -7*count), nil
+	}
+}
+
+// ParseDuration reads the same durations as ParseLast as a fixed length,
+// with days of 24 hours, and falls back to time.ParseDuration for values
+// such as 90m or 1h30m.
+func ParseDuration(value string) (time.Duration, error) {
+	count, unit, err := parseLastCount(value)
+	if err != nil {
+		if duration, durationErr := time.ParseDuration(value); durationErr == nil {
+			return duration, nil
+		}
+		return 0, err
+	}
+	switch unit {
+	case "h":
+		return time.Hour * time.Duration(count), nil
+	case "d":
+		return 24 * time.Hour * time.Duration(count), nil
+	default:
+		return 7 * 24 * time.Hour * time.Duration(count), nil
+	}
+}
+
+func parseLastCount(value string) (int, string, error) {
+	const lastFormat = "a number followed by h, d or w e.g. 3d"
+	match := lastPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, "", &InvalidTimeError{Value: value, Format: lastFormat}
+	}
+	count, err := strconv.Atoi(match[1])
+	if err != nil || count == 0 {
+		return 0, "", &InvalidTimeError{Value: value, Format: lastFormat, Err: err}
+	}
+	return count, match[2], nil
+}
+
+// parseISOWeek returns the Monday starting an ISO 8601 week such as 2026-W41.
+func parseISOWeek(value string, loc *time.Location) (time.Time, error) {
+	const weekFormat = "ISO 8601 week e.g. 2006-W01"
+	match := weekPattern.FindStringSubmatch(value)
+	if match == nil {
+		return time.Time{}, &InvalidTimeError{Value: value, Format: weekFormat}
+	}
+	year, _ := strconv.Atoi(match[1])
+	week, _ := strconv.Atoi(match[2])
+	// January 4th is always in week 1.
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	offset := (int(jan4.Weekday()) + 6) % 7
+	monday := jan4.AddDate(0, 0, -1*offset+7*(week-1))
+	if isoYear, isoWeek := monday.ISOWeek(); week < 1 || isoYear != year || isoWeek != week {
+		return time.Time{}, &InvalidTimeError{Value: value, Format: weekFormat}
+	}
+	return monday, nil
+}
@@ -0,0 +1,142 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseISOWeek(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"2026-W01", "2025-12-29"},
+		{"2026-W41", "2026-10-05"},
+		{"2020-W53", "2020-12-28"},
+		{"2021-W01", "2021-01-04"},
+		{"2026-W53", "2026-12-28"},
+	}
+	for _, test := range tests {
+		monday, err := parseISOWeek(test.value, time.UTC)
+		if err != nil {
+			t.Errorf("parseISOWeek(%s): %v", test.value, err)
+			continue
+		}
+		if got := monday.Format(DAY_LAYOUT); got != test.want {
+			t.Errorf("parseISOWeek(%s) = %s, want %s", test.value, got, test.want)
+		}
+	}
+	for _, value := range []string{"2026-W00", "2025-W53", "2026-41", "2026-W1", "W41"} {
+		var timeErr *InvalidTimeError
+		if _, err := parseISOWeek(value, time.UTC); !errors.As(err, &timeErr) {
+			t.Errorf("parseISOWeek(%s) err = %v, want an InvalidTimeError", value, err)
+		}
+	}
+}
+
+func TestParseISOWeekInLocation(t *testing.T) {
+	loc := time.FixedZone("UTC-7", -7*60*60)
+	monday, err := parseISOWeek("2026-W41", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, time.October, 5, 7, 0, 0, 0, time.UTC); !monday.Equal(want) {
+		t.Errorf("week starts at %v, want %v", monday.UTC(), want)
+	}
+}
+
+func TestParseLast(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"12h", now.Add(-12 * time.Hour)},
+		{"3d", now.AddDate(0, 0, -3)},
+		{"2w", now.AddDate(0, 0, -14)},
+	}
+	for _, test := range tests {
+		since, err := ParseLast(test.value, now)
+		if err != nil {
+			t.Errorf("ParseLast(%s): %v", test.value, err)
+			continue
+		}
+		if !since.Equal(test.want) {
+			t.Errorf("ParseLast(%s) = %v, want %v", test.value, since, test.want)
+		}
+	}
+	for _, value := range []string{"0d", "3", "d", "3m", "-3d", "1.5d"} {
+		var timeErr *InvalidTimeError
+		if _, err := ParseLast(value, now); !errors.As(err, &timeErr) {
+			t.Errorf("ParseLast(%s) err = %v, want an InvalidTimeError", value, err)
+		}
+	}
+}
+
+func TestResolveRange(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, loc)
+	tests := []struct {
+		from, to     string
+		since, until time.Time
+	}{
+		{"2026-10-01", "", time.Date(2026, time.October, 1, 0, 0, 0, 0, loc), now},
+		{"2026-10-01", "2026-10-03", time.Date(2026, time.October, 1, 0, 0, 0, 0, loc), time.Date(2026, time.October, 4, 0, 0, 0, 0, loc)},
+		{"2026-10-01T08:00:00Z", "2026-10-01T09:30:00Z", time.Date(2026, time.October, 1, 8, 0, 0, 0, time.UTC), time.Date(2026, time.October, 1, 9, 30, 0, 0, time.UTC)},
+		{"2026-10-01T08:00:00", "2026-10-02", time.Date(2026, time.October, 1, 8, 0, 0, 0, loc), time.Date(2026, time.October, 3, 0, 0, 0, 0, loc)},
+	}
+	for _, test := range tests {
+		window, err := resolveRange(test.from, test.to, now, loc)
+		if err != nil {
+			t.Errorf("resolveRange(%s, %s): %v", test.from, test.to, err)
+			continue
+		}
+		if !window.Since.Equal(test.since) || !window.Until.Equal(test.until) {
+			t.Errorf("resolveRange(%s, %s) = [%v, %v), want [%v, %v)", test.from, test.to, window.Since, window.Until, test.since, test.until)
+		}
+	}
+	for _, bad := range [][2]string{{"2026-10-03", "2026-10-02"}, {"2026-10-19", ""}, {"yesterday", ""}, {"2026-10-01", "2026-13-01"}} {
+		var timeErr *InvalidTimeError
+		if _, err := resolveRange(bad[0], bad[1], now, loc); !errors.As(err, &timeErr) {
+			t.Errorf("resolveRange(%s, %s) err = %v, want an InvalidTimeError", bad[0], bad[1], err)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"72h":   72 * time.Hour,
+		"3d":    72 * time.Hour,
+		"2w":    14 * 24 * time.Hour,
+		"90m":   90 * time.Minute,
+		"1h30m": 90 * time.Minute,
+	}
+	for value, want := range tests {
+		got, err := ParseDuration(value)
+		if err != nil || got != want {
+			t.Errorf("ParseDuration(%s) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"0d", "3x", "d", ""} {
+		if _, err := ParseDuration(value); err == nil {
+			t.Errorf("ParseDuration(%s) accepted an invalid duration", value)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+)
+
+var (
+	DEFAULT_CONCURRENCY int = 4
+	// Requests kept in reserve per worker before workers pause for the
+	// rate limit window to reset.
+	RATE_LIMIT_RESERVE int = 10
+)
+
+type rateBudget struct {
+	mu   sync.Mutex
+	rate github.Rate
+}
+
+// forEachRepo runs fn for every repo on a bounded pool of workers, stopping
+// at the first error.
+func (p *GithubProxy) forEachRepo(
+	ctx context.Context,
+	repos []string,
+	fn func(ctx context.Context, i int, repo string) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	workers := p.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(repos) {
+		workers = len(repos)
+	}
+	jobs := make(chan int)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := p.waitForRateBudget(ctx, workers); err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+				if err := fn(ctx, i, repos[i]); err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+sendJobs:
+	for i := range repos {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break sendJobs
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+	if err, ok := <-errs; ok {
+		return err
+	}
+	return nil
+}
+
+// rateTransport records the core rate limit of every response, so the
+// workers of all commands see the budget whatever API they call.
+type rateTransport struct {
+	base   http.RoundTripper
+	budget *rateBudget
+}
+
+func (t *rateTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if resp != nil {
+		t.budget.record(resp.Header)
+	}
+	return resp, err
+}
+
+// record keeps the latest rate limit headers. The search API has a separate,
+// much smaller limit that must not be mistaken for the core budget.
+func (b *rateBudget) record(header http.Header) {
+	if resource := header.Get("X-RateLimit-Resource"); resource != "" && resource != "core" {
+		return
+	}
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil || limit == 0 {
+		return
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rate = github.Rate{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     github.Timestamp{Time: time.Unix(reset, 0)},
+	}
+}
+
+// waitForRateBudget pauses a worker until the rate limit window resets when
+// the last known remaining requests drop below the reserve for all workers.
+func (p *GithubProxy) waitForRateBudget(ctx context.Context, workers int) error {
+	p.budget.mu.Lock()
+	rate := p.budget.rate
+	p.budget.mu.Unlock()
+	if rate.Limit == 0 || rate.Remaining >= workers*RATE_LIMIT_RESERVE {
+		return nil
+	}
+	wait := time.Until(rate.Reset.Time)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
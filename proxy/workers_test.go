@@ -0,0 +1,58 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func rateHeader(resource string, remaining int, reset time.Time) http.Header {
+	header := http.Header{}
+	if resource != "" {
+		header.Set("X-RateLimit-Resource", resource)
+	}
+	header.Set("X-RateLimit-Limit", "5000")
+	header.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	return header
+}
+
+func TestRateBudgetRecordsCoreLimit(t *testing.T) {
+	reset := time.Now().Add(time.Minute).Truncate(time.Second)
+	budget := &rateBudget{}
+	budget.record(rateHeader("core", 7, reset))
+	budget.record(rateHeader("search", 29, reset))
+	budget.record(http.Header{})
+	if budget.rate.Remaining != 7 || !budget.rate.Reset.Time.Equal(reset) {
+		t.Errorf("got remaining %d reset %v, want the core limit 7 %v",
+			budget.rate.Remaining, budget.rate.Reset.Time, reset)
+	}
+}
+
+func TestWaitForRateBudget(t *testing.T) {
+	budget := &rateBudget{}
+	budget.record(rateHeader("", 1, time.Now().Add(-time.Second)))
+	p := &GithubProxy{budget: budget}
+	// The reset already passed, so the worker goes on right away.
+	if err := p.waitForRateBudget(context.Background(), 4); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,54 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package render
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/sbuckfelder/github-monitoring-tool/proxy"
+)
+
+type csvRenderer struct{}
+
+func (csvRenderer) RenderEvents(w io.Writer, report *proxy.EventReport) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"Repo", "Section", "Number", "Author", "Title", "URL", "Count"})
+	for _, repo := range report.Repos {
+		writeItemRows(writer, repo.Repo, "merged_pr", repo.MergedPRs)
+		writeItemRows(writer, repo.Repo, "new_pr", repo.NewPRs)
+		writeActivityRows(writer, repo.Repo, "review_activity", repo.ReviewActivity)
+		writeItemRows(writer, repo.Repo, "new_issue", repo.NewIssues)
+		writeItemRows(writer, repo.Repo, "closed_issue", repo.ClosedIssues)
+		writeActivityRows(writer, repo.Repo, "issue_comment_activity", repo.IssueCommentActivity)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeItemRows(writer *csv.Writer, repo, section string, items []proxy.Item) {
+	for _, item := range items {
+		writer.Write([]string{repo, section, strconv.Itoa(item.Number), item.Author, item.Title, item.URL, ""})
+	}
+}
+
+func writeActivityRows(writer *csv.Writer, repo, section string, activity []proxy.Activity) {
+	for _, act := range activity {
+		writer.Write([]string{repo, section, "", "", act.Title, act.URL, strconv.Itoa(act.Count)})
+	}
+}
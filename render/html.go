@@ -0,0 +1,115 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package render
+
+import (
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/sbuckfelder/github-monitoring-tool/proxy"
+)
+
+type htmlRenderer struct{}
+
+type htmlSection struct {
+	Title string
+	Rows  interface{}
+}
+
+var htmlTemplate = template.Must(template.New("events").Funcs(template.FuncMap{
+	"rfc3339": func(t time.Time) string { return t.Format(time.RFC3339) },
+	"section": func(title string, rows interface{}) htmlSection { return htmlSection{title, rows} },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Org}} GitHub Events</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+h1 { font-size: 1.5em; }
+h2 { font-size: 1.25em; border-bottom: 1px solid #d0d7de; padding-bottom: .3em; }
+h3 { font-size: 1em; text-transform: uppercase; color: #57606a; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+td, th { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; }
+.warning { color: #9a6700; }
+footer { margin-top: 2em; font-style: italic; color: #57606a; }
+</style>
+</head>
+<body>
+<h1>{{.Org}} GitHub Events</h1>
+{{- range .Repos}}
+<section>
+<h2><a href="{{.URL}}">{{.Repo}}</a></h2>
+{{- if .Truncated}}
+<p class="warning">Warning: events are only available {{.Coverage}}, this report is incomplete</p>
+{{- end}}
+{{- if .Empty}}
+<p>No Events</p>
+{{- else}}
+{{- template "items" section "PR's Merged" .MergedPRs}}
+{{- template "items" section "New Pull Requests" .NewPRs}}
+{{- template "activity" section "PR Review/Comment Activity" .ReviewActivity}}
+{{- template "items" section "New Issues" .NewIssues}}
+{{- template "items" section "Closed Issues" .ClosedIssues}}
+{{- template "activity" section "Issue Comment Activity" .IssueCommentActivity}}
+<h3>Event Report</h3>
+<table>
+<tr><th>Type</th><th>Count</th></tr>
+{{- range .EventCounts}}
+<tr><td>{{.Type}}</td><td>{{.Count}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</section>
+{{- end}}
+<footer>
+{{- if .Date}}
+Based on Events for {{.Date}}
+{{- else}}
+Based on Events from {{rfc3339 .Since}} to {{rfc3339 .Until}}
+{{- end}}
+</footer>
+</body>
+</html>
+{{- define "items"}}
+{{- if .Rows}}
+<h3>{{.Title}}</h3>
+<table>
+<tr><th>#</th><th>Author</th><th>Title</th></tr>
+{{- range .Rows}}
+<tr><td>{{.Number}}</td><td>{{.Author}}</td><td><a href="{{.URL}}">{{.Title}}</a></td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+{{- define "activity"}}
+{{- if .Rows}}
+<h3>{{.Title}}</h3>
+<table>
+<tr><th>Count</th><th>Title</th></tr>
+{{- range .Rows}}
+<tr><td>{{.Count}}</td><td><a href="{{.URL}}">{{.Title}}</a></td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+`))
+
+func (htmlRenderer) RenderEvents(w io.Writer, report *proxy.EventReport) error {
+	return htmlTemplate.Execute(w, report)
+}
@@ -0,0 +1,68 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package render
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sbuckfelder/github-monitoring-tool/proxy"
+)
+
+type IssueColumn = Column[proxy.IssueSummary]
+
+type IssueOptions = TableOptions[proxy.IssueSummary]
+
+var issueColumns = []IssueColumn{
+	{"Repo", func(issue *proxy.IssueSummary) string { return issue.Repo }},
+	{"Number", func(issue *proxy.IssueSummary) string { return strconv.Itoa(issue.Number) }},
+	{"Title", func(issue *proxy.IssueSummary) string { return issue.Title }},
+	{"URL", func(issue *proxy.IssueSummary) string { return issue.URL }},
+	{"Author", func(issue *proxy.IssueSummary) string { return issue.Author }},
+	{"Labels", func(issue *proxy.IssueSummary) string { return strings.Join(issue.Labels, ";") }},
+	{"Assignees", func(issue *proxy.IssueSummary) string { return strings.Join(issue.Assignees, ";") }},
+	{"Milestone", func(issue *proxy.IssueSummary) string { return issue.Milestone }},
+	{"Created", func(issue *proxy.IssueSummary) string { return issue.CreatedAt.Format(DATE_FORMAT) }},
+	{"Updated", func(issue *proxy.IssueSummary) string { return issue.UpdatedAt.Format(DATE_FORMAT) }},
+	{"Comments", func(issue *proxy.IssueSummary) string { return strconv.Itoa(issue.Comments) }},
+	{"DaysSinceMaintainerResponse", func(issue *proxy.IssueSummary) string {
+		return strconv.Itoa(issue.DaysSinceMaintainerResponse)
+	}},
+	{"LastMaintainerResponseDate", func(issue *proxy.IssueSummary) string {
+		if issue.LastMaintainerResponseAt == nil {
+			return ""
+		}
+		return issue.LastMaintainerResponseAt.Format(DATE_FORMAT)
+	}},
+	{"LastMaintainerResponseBy", func(issue *proxy.IssueSummary) string { return issue.LastMaintainerResponseBy }},
+}
+
+func IssueColumnNames() []string {
+	return columnNames(issueColumns)
+}
+
+func SelectIssueColumns(names []string) ([]IssueColumn, error) {
+	return selectColumns(issueColumns, names)
+}
+
+func IssueReport(w io.Writer, report *proxy.IssueReport, opts IssueOptions) error {
+	if len(opts.Columns) == 0 {
+		opts.Columns = issueColumns
+	}
+	return writeCSV(w, report.Issues, opts)
+}
@@ -0,0 +1,111 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sbuckfelder/github-monitoring-tool/proxy"
+)
+
+var REPORT_SEPERATOR string = strings.Repeat("*", 20)
+
+type markdownRenderer struct{}
+
+func (markdownRenderer) RenderEvents(w io.Writer, report *proxy.EventReport) error {
+	for _, repo := range report.Repos {
+		if report.Date != "" {
+			fmt.Fprintf(w, "%s Events for %s\n", repo.URL, report.Date)
+		} else {
+			fmt.Fprintf(w, "%s Events Since %s\n", repo.URL, report.Since.Format(time.RFC3339))
+		}
+		writeRepoEventReport(w, &repo)
+	}
+	if report.Date != "" {
+		fmt.Fprintf(w, "_Based on Events for %s_\n", report.Date)
+	} else {
+		fmt.Fprintf(w, "_Based on Events from %s to %s_\n",
+			report.Since.Format(time.RFC3339),
+			report.Until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func writeRepoEventReport(w io.Writer, repo *proxy.RepoEventReport) {
+	defer fmt.Fprintf(w, "%s\n", REPORT_SEPERATOR)
+	if repo.Truncated {
+		fmt.Fprintf(w, "_Warning: events are only available %s, this report is incomplete_\n", repo.Coverage())
+	}
+	if repo.Empty() {
+		fmt.Fprintf(w, "No Events\n")
+		return
+	}
+	if len(repo.MergedPRs) != 0 {
+		writeBanner(w, "PR'S MERGED")
+		writeItems(w, repo.Repo, "PR", repo.MergedPRs)
+	}
+	if len(repo.NewPRs) != 0 {
+		writeBanner(w, "NEW PULL REQUESTS")
+		writeItems(w, repo.Repo, "PR", repo.NewPRs)
+	}
+	if len(repo.ReviewActivity) != 0 {
+		writeBanner(w, "PR REVIEW/COMMENT ACTIVITY")
+		writeActivity(w, repo.Repo, "Actions", repo.ReviewActivity)
+	}
+	if len(repo.NewIssues) != 0 {
+		writeBanner(w, "NEW ISSUES")
+		writeItems(w, repo.Repo, "ISSUE", repo.NewIssues)
+	}
+	if len(repo.ClosedIssues) != 0 {
+		writeBanner(w, "CLOSED ISSUES")
+		writeItems(w, repo.Repo, "ISSUE", repo.ClosedIssues)
+	}
+	if len(repo.IssueCommentActivity) != 0 {
+		writeBanner(w, "ISSUE COMMENT ACTIVITY")
+		writeActivity(w, repo.Repo, "Comments", repo.IssueCommentActivity)
+	}
+	writeBanner(w, "EVENT REPORT")
+	for _, count := range repo.EventCounts {
+		fmt.Fprintf(w, "%d %s\n", count.Count, count.Type)
+	}
+}
+
+func writeBanner(w io.Writer, title string) {
+	line := strings.Repeat("=", len(title))
+	fmt.Fprintf(w, "%s\n%s\n%s\n", line, title, line)
+}
+
+func writeItems(w io.Writer, repo, kind string, items []proxy.Item) {
+	for _, item := range items {
+		fmt.Fprintf(w, "- **%s** %s#%d %s: [%s](%s)\n",
+			repo,
+			kind,
+			item.Number,
+			item.Author,
+			item.Title,
+			item.URL)
+	}
+}
+
+func writeActivity(w io.Writer, repo, label string, activity []proxy.Activity) {
+	for _, act := range activity {
+		fmt.Fprintf(w, "%s:%d - **%s** [%s](%s)\n", label, act.Count, repo, act.Title, act.URL)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package render
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sbuckfelder/github-monitoring-tool/proxy"
+)
+
+var DATE_FORMAT string = "2006-Jan-02"
+
+type PullRequestColumn = Column[proxy.PullRequestSummary]
+
+type PullRequestOptions = TableOptions[proxy.PullRequestSummary]
+
+var pullRequestColumns = []PullRequestColumn{
+	{"Repo", func(pr *proxy.PullRequestSummary) string { return pr.Repo }},
+	{"Number", func(pr *proxy.PullRequestSummary) string { return strconv.Itoa(pr.Number) }},
+	{"Title", func(pr *proxy.PullRequestSummary) string { return pr.Title }},
+	{"URL", func(pr *proxy.PullRequestSummary) string { return pr.URL }},
+	{"Draft", func(pr *proxy.PullRequestSummary) string { return strconv.FormatBool(pr.Draft) }},
+	{"DaysSinceLastAction", func(pr *proxy.PullRequestSummary) string { return strconv.Itoa(pr.DaysSinceLastAction) }},
+	{"Created", func(pr *proxy.PullRequestSummary) string { return pr.CreatedAt.Format(DATE_FORMAT) }},
+	{"Updated", func(pr *proxy.PullRequestSummary) string { return pr.UpdatedAt.Format(DATE_FORMAT) }},
+	{"Author", func(pr *proxy.PullRequestSummary) string { return pr.Author }},
+	{"Base", func(pr *proxy.PullRequestSummary) string { return pr.Base }},
+	{"Labels", func(pr *proxy.PullRequestSummary) string { return strings.Join(pr.Labels, ";") }},
+	{"Comments", func(pr *proxy.PullRequestSummary) string { return strconv.Itoa(pr.Comments) }},
+	{"LastCommentDate", func(pr *proxy.PullRequestSummary) string {
+		if pr.LastCommentAt == nil {
+			return ""
+		}
+		return pr.LastCommentAt.Format(DATE_FORMAT)
+	}},
+	{"CommentAuthor", func(pr *proxy.PullRequestSummary) string { return pr.LastCommentAuthor }},
+	{"LastActionDate", func(pr *proxy.PullRequestSummary) string { return pr.LastActionAt.Format(DATE_FORMAT) }},
+	{"LastActionKind", func(pr *proxy.PullRequestSummary) string { return pr.LastActionKind }},
+	{"LastActionBy", func(pr *proxy.PullRequestSummary) string { return pr.LastActionBy }},
+	{"ReviewDecision", func(pr *proxy.PullRequestSummary) string { return pr.ReviewDecision }},
+	{"Reviews", func(pr *proxy.PullRequestSummary) string {
+		var reviews []string
+		for _, review := range pr.Reviews {
+			reviews = append(reviews, review.Reviewer+":"+review.State)
+		}
+		return strings.Join(reviews, ";")
+	}},
+	{"RequestedReviewers", func(pr *proxy.PullRequestSummary) string { return strings.Join(pr.RequestedReviewers, ";") }},
+	{"WaitingOn", func(pr *proxy.PullRequestSummary) string { return pr.WaitingOn }},
+	{"CIStatus", func(pr *proxy.PullRequestSummary) string { return pr.CIStatus }},
+	{"CommitStatus", func(pr *proxy.PullRequestSummary) string { return pr.CommitStatus }},
+	{"CheckStatus", func(pr *proxy.PullRequestSummary) string { return pr.CheckStatus }},
+	{"MergeableState", func(pr *proxy.PullRequestSummary) string { return pr.MergeableState }},
+	{"BehindBy", func(pr *proxy.PullRequestSummary) string { return strconv.Itoa(pr.BehindBy) }},
+}
+
+// Columns that need the CI status and mergeability of every pull request,
+// which costs several more API calls per pull request.
+var statusColumns = map[string]bool{
+	"CIStatus":       true,
+	"CommitStatus":   true,
+	"CheckStatus":    true,
+	"MergeableState": true,
+	"BehindBy":       true,
+}
+
+// defaultPullRequestColumns leaves out the status columns, they have to be
+// asked for with --columns.
+func defaultPullRequestColumns() []PullRequestColumn {
+	var columns []PullRequestColumn
+	for _, column := range pullRequestColumns {
+		if !statusColumns[column.Name] {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+func PullRequestDetails(columns []PullRequestColumn) proxy.PullRequestDetails {
+	if len(columns) == 0 {
+		columns = defaultPullRequestColumns()
+	}
+	details := proxy.PullRequestDetails{}
+	for _, column := range columns {
+		if statusColumns[column.Name] {
+			details.Status = true
+		}
+	}
+	return details
+}
+
+func PullRequestColumnNames() []string {
+	return columnNames(pullRequestColumns)
+}
+
+func SelectPullRequestColumns(names []string) ([]PullRequestColumn, error) {
+	if len(names) == 0 {
+		return defaultPullRequestColumns(), nil
+	}
+	return selectColumns(pullRequestColumns, names)
+}
+
+func PullRequestReport(w io.Writer, report *proxy.PullRequestReport, opts PullRequestOptions) error {
+	if len(opts.Columns) == 0 {
+		opts.Columns = defaultPullRequestColumns()
+	}
+	return writeCSV(w, report.PullRequests, opts)
+}
@@ -0,0 +1,61 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package render
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/sbuckfelder/github-monitoring-tool/proxy"
+)
+
+const DEFAULT_FORMAT string = "markdown"
+
+type EventRenderer interface {
+	RenderEvents(w io.Writer, report *proxy.EventReport) error
+}
+
+var eventRenderers = map[string]EventRenderer{
+	"markdown": markdownRenderer{},
+	"json":     jsonRenderer{},
+	"csv":      csvRenderer{},
+	"html":     htmlRenderer{},
+}
+
+func RegisterEventRenderer(format string, renderer EventRenderer) {
+	eventRenderers[format] = renderer
+}
+
+func NewEventRenderer(format string) (EventRenderer, error) {
+	renderer, ok := eventRenderers[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("Unknown output format '%s' must be one of [%s]",
+			format, strings.Join(EventFormats(), ","))
+	}
+	return renderer, nil
+}
+
+func EventFormats() []string {
+	var formats []string
+	for format := range eventRenderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
@@ -0,0 +1,144 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sbuckfelder/github-monitoring-tool/proxy"
+)
+
+type ResponseRenderer interface {
+	RenderResponses(w io.Writer, report *proxy.ResponseReport) error
+}
+
+var responseRenderers = map[string]ResponseRenderer{
+	"markdown": markdownRenderer{},
+	"json":     jsonRenderer{},
+	"csv":      csvRenderer{},
+}
+
+func NewResponseRenderer(format string) (ResponseRenderer, error) {
+	renderer, ok := responseRenderers[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("Unknown output format '%s' must be one of [%s]",
+			format, strings.Join(ResponseFormats(), ","))
+	}
+	return renderer, nil
+}
+
+func ResponseFormats() []string {
+	var formats []string
+	for format := range responseRenderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+func (markdownRenderer) RenderResponses(w io.Writer, report *proxy.ResponseReport) error {
+	unanswered := report.Unanswered()
+	slow := report.SlowResponses()
+	fmt.Fprintf(w, "%s Maintainer Response Report, threshold %s\n", report.Org, formatDuration(report.Threshold))
+	if len(unanswered) != 0 {
+		writeBanner(w, "NO MAINTAINER RESPONSE")
+		for _, item := range unanswered {
+			fmt.Fprintf(w, "%s waiting %s\n", responseItemLine(item), formatDuration(item.TimeToFirstResponse))
+		}
+	}
+	if len(slow) != 0 {
+		writeBanner(w, "SLOW FIRST RESPONSE")
+		for _, item := range slow {
+			fmt.Fprintf(w, "%s answered by %s after %s\n",
+				responseItemLine(item), item.FirstResponseBy, formatDuration(item.TimeToFirstResponse))
+		}
+	}
+	if len(unanswered) == 0 && len(slow) == 0 {
+		fmt.Fprintf(w, "No items over the threshold\n")
+	}
+	fmt.Fprintf(w, "%s\n", REPORT_SEPERATOR)
+	fmt.Fprintf(w, "_%d open items, %d without a maintainer response, %d over the threshold_\n",
+		len(report.Items),
+		len(report.Items)-countResponded(report.Items),
+		len(unanswered)+len(slow))
+	return nil
+}
+
+func responseItemLine(item proxy.ResponseItem) string {
+	kind := "ISSUE"
+	if item.Kind == proxy.KIND_PULL_REQUEST {
+		kind = "PR"
+	}
+	return fmt.Sprintf("- **%s** %s#%d %s: [%s](%s)", item.Repo, kind, item.Number, item.Author, item.Title, item.URL)
+}
+
+func countResponded(items []proxy.ResponseItem) int {
+	count := 0
+	for _, item := range items {
+		if item.Responded() {
+			count++
+		}
+	}
+	return count
+}
+
+func formatDuration(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	if days == 0 {
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dd %dh", days, hours)
+}
+
+func (jsonRenderer) RenderResponses(w io.Writer, report *proxy.ResponseReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(report)
+}
+
+var responseColumns = []Column[proxy.ResponseItem]{
+	{"Repo", func(item *proxy.ResponseItem) string { return item.Repo }},
+	{"Kind", func(item *proxy.ResponseItem) string { return item.Kind }},
+	{"Number", func(item *proxy.ResponseItem) string { return strconv.Itoa(item.Number) }},
+	{"Title", func(item *proxy.ResponseItem) string { return item.Title }},
+	{"URL", func(item *proxy.ResponseItem) string { return item.URL }},
+	{"Author", func(item *proxy.ResponseItem) string { return item.Author }},
+	{"Created", func(item *proxy.ResponseItem) string { return item.CreatedAt.Format(DATE_FORMAT) }},
+	{"FirstResponseDate", func(item *proxy.ResponseItem) string {
+		if item.FirstResponseAt == nil {
+			return ""
+		}
+		return item.FirstResponseAt.Format(DATE_FORMAT)
+	}},
+	{"FirstResponseBy", func(item *proxy.ResponseItem) string { return item.FirstResponseBy }},
+	{"HoursToFirstResponse", func(item *proxy.ResponseItem) string {
+		return strconv.Itoa(int(item.TimeToFirstResponse.Hours()))
+	}},
+	{"Breached", func(item *proxy.ResponseItem) string { return strconv.FormatBool(item.Breached) }},
+}
+
+func (csvRenderer) RenderResponses(w io.Writer, report *proxy.ResponseReport) error {
+	return writeCSV(w, report.Items, TableOptions[proxy.ResponseItem]{Columns: responseColumns})
+}
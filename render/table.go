@@ -0,0 +1,80 @@
+/*
+   Copyright awslabs Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package render
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Column[T any] struct {
+	Name  string
+	Value func(row *T) string
+}
+
+type TableOptions[T any] struct {
+	Columns  []Column[T]
+	NoHeader bool
+}
+
+func columnNames[T any](all []Column[T]) []string {
+	var names []string
+	for _, column := range all {
+		names = append(names, column.Name)
+	}
+	return names
+}
+
+func selectColumns[T any](all []Column[T], names []string) ([]Column[T], error) {
+	if len(names) == 0 {
+		return all, nil
+	}
+	var columns []Column[T]
+	for _, name := range names {
+		found := false
+		for _, column := range all {
+			if strings.EqualFold(column.Name, name) {
+				columns = append(columns, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Unknown column '%s' must be one of [%s]",
+				name, strings.Join(columnNames(all), ","))
+		}
+	}
+	return columns, nil
+}
+
+func writeCSV[T any](w io.Writer, rows []T, opts TableOptions[T]) error {
+	writer := csv.NewWriter(w)
+	if !opts.NoHeader {
+		writer.Write(columnNames(opts.Columns))
+	}
+	for i := range rows {
+		var row []string
+		for _, column := range opts.Columns {
+			row = append(row, column.Value(&rows[i]))
+		}
+		writer.Write(row)
+	}
+	writer.Flush()
+	return writer.Error()
+}